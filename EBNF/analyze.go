@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ============================================================================
+// ANALYZE - Classifying every line of a file
+// ============================================================================
+//
+// ClassifyReader is RunREPL without the prompts: it runs ClassifyStatement
+// over each line of a file and collects the results instead of printing
+// them. Comments are stripped first, block comments included even when
+// they span lines, and lines left blank are skipped. A line that fails to
+// parse gets its error in Err and the scan carries on; only a failure to
+// read stops it.
+
+type StatementInfo struct {
+	Line int // 1-based
+	Kind string
+	Node Node  // nil for kinds ClassifyStatement doesn't parse
+	Err  error // why the line failed to parse, if it did
+}
+
+func ClassifyReader(r io.Reader) ([]StatementInfo, error) {
+	var infos []StatementInfo
+	sc := bufio.NewScanner(r)
+	inComment := false
+	for n := 1; sc.Scan(); n++ {
+		var code string
+		code, inComment = stripComments(sc.Text(), inComment)
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		kind, node, err := ClassifyStatement(code)
+		infos = append(infos, StatementInfo{Line: n, Kind: kind, Node: node, Err: err})
+	}
+	return infos, sc.Err()
+}
+
+// stripComments removes the comments from one line of a file. inComment
+// says the line starts inside a block comment left open by an earlier
+// line, and the result says whether this one leaves a block comment open.
+// As in StripBlockComments, a comment within the line becomes one space.
+func stripComments(line string, inComment bool) (code string, stillInComment bool) {
+	if inComment {
+		end := strings.Index(line, "*/")
+		if end == -1 {
+			return "", true
+		}
+		line = " " + line[end+len("*/"):]
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '"' || line[i] == '\'' || line[i] == '`':
+			end := skipQuoted(line, i)
+			if end == -1 {
+				b.WriteString(line[i:])
+				return b.String(), false
+			}
+			b.WriteString(line[i : end+1])
+			i = end
+		case strings.HasPrefix(line[i:], "//"):
+			return b.String(), false
+		case strings.HasPrefix(line[i:], "/*"):
+			end := strings.Index(line[i+2:], "*/")
+			if end == -1 {
+				return b.String(), true
+			}
+			b.WriteByte(' ')
+			i += 2 + end + 1
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String(), false
+}
+
+// Example usage:
+// ClassifyReader(strings.NewReader("// setup\ndefer f.Close()\n\nfro x := range xs"))
+// // [{Line: 2, Kind: "defer", Node: FunctionCall{...}}
+// //  {Line: 4, Err: unknown statement "fro": did you mean "for"?}]
+// stripComments("x := 1 /* one */ // set x", false)  // "x := 1  ", false
+// stripComments("f() /* starts here", false)         // "f() ", true
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClassifyReader(t *testing.T) {
+	src := strings.Join([]string{
+		"package main",
+		"",
+		"// Process closes f when it is done.",
+		"for i := 0; i < 3; i++ {",
+		"	defer f.Close() // always",
+		"	/* a comment",
+		"	   that spans lines */",
+		"fro x := range xs",
+		"	x = y + 1 /* inline */",
+		"	fmt.Println(\"// not a comment\")",
+		"	return a, b",
+		"var x int",
+	}, "\n")
+
+	infos, err := ClassifyReader(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		line    int
+		kind    string
+		wantErr bool
+	}{
+		{1, "package", false},
+		{4, "for", false},
+		{5, "defer", false},
+		{8, "", true},
+		{9, "expression", false},
+		{10, "expression", false},
+		{11, "return", false},
+		{12, "var", false},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("got %d results want %d: %+v", len(infos), len(want), infos)
+	}
+	for i, w := range want {
+		got := infos[i]
+		if got.Line != w.line || got.Kind != w.kind || (got.Err != nil) != w.wantErr {
+			t.Errorf("result %d = {Line: %d, Kind: %q, Err: %v} want line %d, kind %q, error %t",
+				i, got.Line, got.Kind, got.Err, w.line, w.kind, w.wantErr)
+		}
+	}
+
+	if got := infos[4].Node; got != RawExpr("x = y + 1") {
+		t.Errorf("line 9 node = %#v want %#v", got, RawExpr("x = y + 1"))
+	}
+	if got := infos[5].Node; got != RawExpr(`fmt.Println("// not a comment")`) {
+		t.Errorf("line 10 node = %#v, the string must be kept", got)
+	}
+}
+
+func TestClassifyReaderReadError(t *testing.T) {
+	if _, err := ClassifyReader(failingReader{}); err == nil {
+		t.Errorf("expected the read error to be returned")
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		line          string
+		inComment     bool
+		want          string
+		wantInComment bool
+	}{
+		{"x := 1 // set x", false, "x := 1 ", false},
+		{"x := 1 /* one */ + 2", false, "x := 1   + 2", false},
+		{"f() /* starts here", false, "f() ", true},
+		{"still inside", true, "", true},
+		{"ends */ g()", true, "  g()", false},
+		{"ends */ g() /* again", true, "  g() ", true},
+		{`s := "/* not */ // a comment"`, false, `s := "/* not */ // a comment"`, false},
+		{"r := '/'", false, "r := '/'", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, inComment := stripComments(tt.line, tt.inComment)
+			if got != tt.want || inComment != tt.wantInComment {
+				t.Errorf("stripComments(%q, %t) = %q, %t want %q, %t",
+					tt.line, tt.inComment, got, inComment, tt.want, tt.wantInComment)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+// ============================================================================
+// AST - A common shape for parser results
+// ============================================================================
+//
+// Each parser returns its own struct, but they all implement Node so a caller
+// can walk a parsed structure without knowing every concrete type. Parts a
+// parser keeps as source text (arguments, conditions, ...) appear as RawExpr
+// leaves.
+
+type Node interface {
+	Kind() string
+	Children() []Node
+}
+
+// RawExpr is source text that has not been parsed further.
+type RawExpr string
+
+func (RawExpr) Kind() string     { return "expr" }
+func (RawExpr) Children() []Node { return nil }
+
+// Walk visits n and then its children depth-first. If visit returns false
+// the children of that node are skipped.
+func Walk(n Node, visit func(Node) bool) {
+	if !visit(n) {
+		return
+	}
+	for _, child := range n.Children() {
+		Walk(child, visit)
+	}
+}
+
+// rawExprs wraps each non-empty string as a RawExpr leaf.
+func rawExprs(parts ...string) []Node {
+	var nodes []Node
+	for _, p := range parts {
+		if p != "" {
+			nodes = append(nodes, RawExpr(p))
+		}
+	}
+	return nodes
+}
+
+func (fc FunctionCall) Kind() string     { return "call" }
+func (fc FunctionCall) Children() []Node { return rawExprs(fc.Arguments...) }
+
+func (fs ForStatement) Kind() string     { return "for" }
+func (fs ForStatement) Children() []Node { return rawExprs(fs.Content) }
+
+func (is IfStatement) Kind() string     { return "if" }
+func (is IfStatement) Children() []Node { return rawExprs(is.Init, is.Condition) }
+
+func (ss SwitchStatement) Kind() string     { return "switch" }
+func (ss SwitchStatement) Children() []Node { return rawExprs(ss.Init, ss.Tag) }
+
+func (a Assignment) Kind() string { return "assign" }
+func (a Assignment) Children() []Node {
+	return append(rawExprs(a.LHS...), rawExprs(a.RHS...)...)
+}
+
+func (rs ReturnStatement) Kind() string     { return "return" }
+func (rs ReturnStatement) Children() []Node { return rawExprs(rs.Values...) }
+
+func (ix IndexExpr) Kind() string     { return "index" }
+func (ix IndexExpr) Children() []Node { return rawExprs(ix.Base, ix.Index) }
+
+func (se SliceExpr) Kind() string     { return "slice" }
+func (se SliceExpr) Children() []Node { return rawExprs(se.Base, se.Low, se.High, se.Max) }
+
+func (cl CompositeLiteral) Kind() string { return "composite" }
+func (cl CompositeLiteral) Children() []Node {
+	var nodes []Node
+	for _, e := range cl.Elements {
+		nodes = append(nodes, rawExprs(e.Key, e.Value)...)
+	}
+	return nodes
+}
+
+func (be BinaryExpr) Kind() string     { return "binary" }
+func (be BinaryExpr) Children() []Node { return []Node{be.Left, be.Right} }
+
+func (ue UnaryExpr) Kind() string     { return "unary" }
+func (ue UnaryExpr) Children() []Node { return []Node{ue.Operand} }
+
+func (ta TypeAssertion) Kind() string     { return "assert" }
+func (ta TypeAssertion) Children() []Node { return rawExprs(ta.X) }
+
+func (is ImportSpec) Kind() string     { return "import" }
+func (is ImportSpec) Children() []Node { return rawExprs(is.Name, is.Path) }
+
+func (ib ImportBlock) Kind() string { return "import" }
+func (ib ImportBlock) Children() []Node {
+	var nodes []Node
+	for _, spec := range ib {
+		nodes = append(nodes, spec)
+	}
+	return nodes
+}
+
+func (vs ValueSpec) Kind() string { return "spec" }
+func (vs ValueSpec) Children() []Node {
+	return append(rawExprs(vs.Names...), rawExprs(append([]string{vs.Type}, vs.Values...)...)...)
+}
+
+func (td TypeDef) Kind() string     { return "typedef" }
+func (td TypeDef) Children() []Node { return rawExprs(td.Name, td.Type) }
+
+func (gd GenDecl) Kind() string { return gd.Keyword }
+func (gd GenDecl) Children() []Node {
+	var nodes []Node
+	for _, spec := range gd.Specs {
+		nodes = append(nodes, spec)
+	}
+	return nodes
+}
+
+// Example usage:
+// fc, _ := parseFunctionCall("add(2, 3)")
+// Walk(fc, func(n Node) bool { fmt.Println(n.Kind()); return true })
+// // call, expr, expr
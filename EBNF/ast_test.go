@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// kinds collects the Kind of every node Walk visits, in order.
+func kinds(n Node) []string {
+	var got []string
+	Walk(n, func(n Node) bool {
+		got = append(got, n.Kind())
+		return true
+	})
+	return got
+}
+
+func TestWalk(t *testing.T) {
+	t.Run("function call", func(t *testing.T) {
+		fc, err := parseFunctionCall("add(2, 3)")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"call", "expr", "expr"}
+		if got := kinds(fc); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v want %v", got, want)
+		}
+		if got := fc.Children()[1]; got != RawExpr("3") {
+			t.Errorf("second argument got %v want %q", got, "3")
+		}
+	})
+	t.Run("for statement", func(t *testing.T) {
+		fs, err := parseForStatement("for i := 0; i < 10; i++ { }")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"for", "expr"}
+		if got := kinds(fs); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+	t.Run("expressions", func(t *testing.T) {
+		ix, _ := parseIndexExpr("m[k]")
+		se, _ := parseSliceExpr("s[:n]")
+		if got, want := kinds(ix), []string{"index", "expr", "expr"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("index got %v want %v", got, want)
+		}
+		if got, want := kinds(se), []string{"slice", "expr", "expr"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("slice got %v want %v", got, want)
+		}
+	})
+	t.Run("declarations", func(t *testing.T) {
+		gd, _ := parseGenDecl("var x, y int = 1, 2")
+		want := []string{"var", "spec", "expr", "expr", "expr", "expr", "expr"}
+		if got := kinds(gd); !reflect.DeepEqual(got, want) {
+			t.Errorf("var got %v want %v", got, want)
+		}
+		specs, _ := parseImportBlock("import (\n\"fmt\"\nio \"io\"\n)")
+		want = []string{"import", "import", "expr", "import", "expr", "expr"}
+		if got := kinds(ImportBlock(specs)); !reflect.DeepEqual(got, want) {
+			t.Errorf("import got %v want %v", got, want)
+		}
+	})
+	t.Run("returning false skips children", func(t *testing.T) {
+		fc, _ := parseFunctionCall("f(a, b)")
+		visited := 0
+		Walk(fc, func(Node) bool {
+			visited++
+			return false
+		})
+		if visited != 1 {
+			t.Errorf("visited %d nodes want 1", visited)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// BUILD CONSTRAINTS - The "//go:build" expression grammar
+// ============================================================================
+// EBNF: BuildExpr = AndExpr { "||" AndExpr } .
+//       AndExpr   = UnaryExpr { "&&" UnaryExpr } .
+//       UnaryExpr = "!" UnaryExpr | "(" BuildExpr ")" | tag .
+//       tag       = ( letter | digit | "_" | "." ) { letter | digit | "_" | "." } .
+//
+// The same alternation and grouping as an EBNF production, with && binding
+// tighter than ||, so "a || b && c" is "a || (b && c)". parseBuildConstraint
+// accepts the expression with or without its "//go:build" prefix and
+// returns a tree that Eval runs against a set of tags.
+
+type Expr interface {
+	Eval(tags map[string]bool) bool
+	String() string
+}
+
+type (
+	TagExpr struct{ Tag string }
+	NotExpr struct{ X Expr }
+	AndExpr struct{ X, Y Expr }
+	OrExpr  struct{ X, Y Expr }
+)
+
+func (e TagExpr) Eval(tags map[string]bool) bool { return tags[e.Tag] }
+func (e NotExpr) Eval(tags map[string]bool) bool { return !e.X.Eval(tags) }
+func (e AndExpr) Eval(tags map[string]bool) bool { return e.X.Eval(tags) && e.Y.Eval(tags) }
+func (e OrExpr) Eval(tags map[string]bool) bool  { return e.X.Eval(tags) || e.Y.Eval(tags) }
+
+func (e TagExpr) String() string { return e.Tag }
+func (e NotExpr) String() string { return "!" + groupedConstraint(e.X) }
+func (e AndExpr) String() string { return groupedConstraint(e.X) + " && " + groupedConstraint(e.Y) }
+func (e OrExpr) String() string  { return groupedConstraint(e.X) + " || " + groupedConstraint(e.Y) }
+
+// groupedConstraint parenthesises binary operands so String never depends
+// on precedence to read back the same tree.
+func groupedConstraint(e Expr) string {
+	switch e.(type) {
+	case AndExpr, OrExpr:
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+func parseBuildConstraint(s string) (Expr, error) {
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(s), "//go:build"); ok {
+		s = rest
+	}
+	if strings.TrimSpace(s) == "" {
+		return nil, errEmptyInput
+	}
+	toks, err := tokenizeConstraint(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &grammarParser{toks: toks, end: len(s)}
+	expr, err := parseConstraintOr(p)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != "EOF" {
+		return nil, fmt.Errorf("unexpected %s at offset %d", tok.kind, tok.pos)
+	}
+	return expr, nil
+}
+
+func tokenizeConstraint(s string) ([]grammarToken, error) {
+	var toks []grammarToken
+	for i := 0; i < len(s); {
+		c, width := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += width
+		case strings.HasPrefix(s[i:], "&&"), strings.HasPrefix(s[i:], "||"):
+			toks = append(toks, grammarToken{kind: s[i : i+2], pos: i})
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			toks = append(toks, grammarToken{kind: string(c), pos: i})
+			i += width
+		case isTagChar(c):
+			start := i
+			for i < len(s) {
+				c, width := utf8.DecodeRuneInString(s[i:])
+				if !isTagChar(c) {
+					break
+				}
+				i += width
+			}
+			toks = append(toks, grammarToken{kind: "tag", text: s[start:i], pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+func isTagChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '.'
+}
+
+func parseConstraintOr(p *grammarParser) (Expr, error) {
+	x, err := parseConstraintAnd(p)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == "||" {
+		p.pos++
+		y, err := parseConstraintAnd(p)
+		if err != nil {
+			return nil, err
+		}
+		x = OrExpr{X: x, Y: y}
+	}
+	return x, nil
+}
+
+func parseConstraintAnd(p *grammarParser) (Expr, error) {
+	x, err := parseConstraintUnary(p)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == "&&" {
+		p.pos++
+		y, err := parseConstraintUnary(p)
+		if err != nil {
+			return nil, err
+		}
+		x = AndExpr{X: x, Y: y}
+	}
+	return x, nil
+}
+
+func parseConstraintUnary(p *grammarParser) (Expr, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case "!":
+		p.pos++
+		x, err := parseConstraintUnary(p)
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{X: x}, nil
+	case "(":
+		p.pos++
+		x, err := parseConstraintOr(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case "tag":
+		p.pos++
+		return TagExpr{Tag: tok.text}, nil
+	}
+	return nil, fmt.Errorf("expected tag, found %s at offset %d", tok.kind, tok.pos)
+}
+
+// Example usage:
+// e, _ := parseBuildConstraint("linux && (amd64 || arm64)")
+// e.Eval(map[string]bool{"linux": true, "arm64": true})  // true
+// e.Eval(map[string]bool{"darwin": true, "arm64": true}) // false
+// parseBuildConstraint("//go:build !windows")            // NotExpr{TagExpr{windows}}
+// parseBuildConstraint("linux &&")                       // error: expected tag, found EOF
@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestParseBuildConstraint(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // String of the parsed tree
+	}{
+		{"linux", "linux"},
+		{"//go:build linux", "linux"},
+		{"!windows", "!windows"},
+		{"linux && (amd64 || arm64)", "linux && (amd64 || arm64)"},
+		{"a || b && c", "a || (b && c)"},
+		{"a && b || c", "(a && b) || c"},
+		{"!(a || b)", "!(a || b)"},
+		{"go1.21 && !purego", "go1.21 && !purego"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseBuildConstraint(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %q want %q", got.String(), tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"",
+		"//go:build",
+		"linux &&",
+		"&& linux",
+		"(linux",
+		"linux)",
+		"linux amd64",
+		"linux & amd64",
+		"!",
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseBuildConstraint(input); err == nil {
+				t.Errorf("parseBuildConstraint(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestBuildConstraintEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		tags []string
+		want bool
+	}{
+		{"linux && (amd64 || arm64)", []string{"linux", "amd64"}, true},
+		{"linux && (amd64 || arm64)", []string{"linux", "arm64"}, true},
+		{"linux && (amd64 || arm64)", []string{"linux", "386"}, false},
+		{"linux && (amd64 || arm64)", []string{"darwin", "arm64"}, false},
+		{"!windows", []string{"linux"}, true},
+		{"!windows", []string{"windows"}, false},
+		{"a || b && c", []string{"a"}, true},
+		{"a || b && c", []string{"b"}, false},
+		{"!(a || b)", nil, true},
+	}
+
+	for _, tt := range tests {
+		e, err := parseBuildConstraint(tt.expr)
+		if err != nil {
+			t.Fatalf("parseBuildConstraint(%q): %v", tt.expr, err)
+		}
+		tags := map[string]bool{}
+		for _, tag := range tt.tags {
+			tags[tag] = true
+		}
+		if got := e.Eval(tags); got != tt.want {
+			t.Errorf("%q with %v = %v, want %v", tt.expr, tt.tags, got, tt.want)
+		}
+	}
+}
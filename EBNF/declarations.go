@@ -0,0 +1,605 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// DECLARATIONS - Translating Go's declaration grammar
+// ============================================================================
+//
+// Like the "complete examples" in ebnf_go_examples.go, each parser here
+// follows one production from https://go.dev/ref/spec#Declarations_and_scope
+
+// ============================================================================
+// 1. STRUCT FIELD
+// ============================================================================
+// EBNF: FieldDecl = ( IdentifierList Type | EmbeddedField ) [ Tag ] .
+//       EmbeddedField = [ "*" ] TypeName .
+//       IdentifierList = identifier { "," identifier } .
+//       Tag = string_lit .
+
+type StructField struct {
+	Names []string // empty for an embedded field
+	Type  string
+	Tag   string // optional: the tag's value, without its quotes
+}
+
+func parseStructField(s string) (StructField, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return StructField{}, errEmptyInput
+	}
+
+	field := StructField{}
+
+	// Optional tag: a raw or interpreted string_lit at the end
+	if open := indexTopLevelQuote(s); open != -1 {
+		end := skipQuoted(s, open)
+		switch {
+		case end == -1:
+			return StructField{}, fmt.Errorf("unterminated struct tag in %q", s)
+		case end != len(s)-1:
+			return StructField{}, fmt.Errorf("unexpected %q after struct tag", s[end+1:])
+		}
+		tag, err := strconv.Unquote(s[open:])
+		if err != nil {
+			return StructField{}, fmt.Errorf("invalid struct tag %s", s[open:])
+		}
+		field.Tag = tag
+		s = strings.TrimSpace(s[:open])
+	}
+
+	// Embedded field: [ "*" ] TypeName, e.g. "*Node" or "io.Reader"
+	if strings.HasPrefix(s, "*") {
+		return parseEmbeddedField(field, s)
+	}
+
+	// IdentifierList: identifier { "," identifier }
+	rest := s
+	for {
+		name := leadingIdentifier(rest)
+		if err := validateIdentifier(name); err != nil {
+			return StructField{}, fmt.Errorf("invalid field name: %w", err)
+		}
+		if isKeyword(name) {
+			return StructField{}, fmt.Errorf("invalid field name: %q is a keyword", name)
+		}
+		field.Names = append(field.Names, name)
+
+		rest = strings.TrimSpace(rest[len(name):])
+		if !strings.HasPrefix(rest, ",") {
+			break
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+
+	// A lone name with no type (or a qualified name) is an embedded field
+	if len(field.Names) == 1 && (rest == "" || strings.HasPrefix(rest, ".")) {
+		field.Names = nil
+		return parseEmbeddedField(field, s)
+	}
+
+	if rest == "" {
+		return StructField{}, fmt.Errorf("missing type for fields %v", field.Names)
+	}
+	if err := validateDeclType(rest); err != nil {
+		return StructField{}, fmt.Errorf("invalid type %q for fields %v: %w", rest, field.Names, err)
+	}
+	field.Type = rest
+	return field, nil
+}
+
+// parseEmbeddedField validates an EmbeddedField type name and stores it.
+func parseEmbeddedField(field StructField, typeName string) (StructField, error) {
+	for _, part := range strings.Split(strings.TrimPrefix(typeName, "*"), ".") {
+		if err := validateIdentifier(part); err != nil {
+			return StructField{}, fmt.Errorf("invalid embedded field %q: %w", typeName, err)
+		}
+		if isKeyword(part) {
+			return StructField{}, fmt.Errorf("invalid embedded field %q: %q is a keyword", typeName, part)
+		}
+	}
+	field.Type = typeName
+	return field, nil
+}
+
+// indexTopLevelQuote returns the index of the first quote in s that is not
+// inside brackets, where a tag can start, or -1.
+func indexTopLevelQuote(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '"', '`':
+			if depth == 0 {
+				return i
+			}
+			if i = skipQuoted(s, i); i == -1 {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// leadingIdentifier returns the run of identifier characters at the start of
+// s. The result is not validated; it may start with a digit or be empty.
+func leadingIdentifier(s string) string {
+	for i, c := range s {
+		if !isIdentLetter(c) && !isUnicodeDigit(c) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// Example usage:
+// parseStructField("Name string")              // {Names: [Name], Type: "string"}
+// parseStructField("Age int `json:\"age\"`")   // {Names: [Age], Type: "int", Tag: `json:"age"`}
+// parseStructField("X, Y int")                 // {Names: [X Y], Type: "int"}
+// parseStructField("io.Reader")                // {Type: "io.Reader"} (embedded)
+// parseStructField(`ID int "json:\"id\""`)      // {Names: [ID], Type: "int", Tag: `json:"id"`}
+// parseStructField("type int")                 // error: "type" is a keyword
+
+// ============================================================================
+// 2. PACKAGE CLAUSE
+// ============================================================================
+// EBNF: PackageClause = "package" PackageName .
+//       PackageName = identifier .
+//
+// The PackageName must not be the blank identifier.
+
+func parsePackageClause(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errEmptyInput
+	}
+	if !isKeywordPrefix(s, "package") {
+		return "", fmt.Errorf("not a package clause")
+	}
+
+	name := strings.TrimSpace(strings.TrimPrefix(s, "package"))
+	if name == "" {
+		return "", fmt.Errorf("missing package name")
+	}
+	if err := validateIdentifier(name); err != nil {
+		return "", fmt.Errorf("invalid package name: %w", err)
+	}
+	if name == "_" || isKeyword(name) {
+		return "", fmt.Errorf("invalid package name %q", name)
+	}
+	return name, nil
+}
+
+// Example usage:
+// parsePackageClause("package main")    // "main"
+// parsePackageClause("package my_pkg")  // "my_pkg"
+// parsePackageClause("package 123")     // error
+
+// ============================================================================
+// 3. STRUCT TAGS
+// ============================================================================
+// A Tag is just a string_lit to the grammar, but by convention (see
+// reflect.StructTag) it holds space-separated key:"value" pairs:
+//
+//	json:"name,omitempty" xml:"n"
+//
+// Keys are runs of characters other than space, quote, colon, and control
+// characters; values are double-quoted Go strings and may contain spaces.
+
+func parseStructTag(tag string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for rest := strings.TrimLeft(tag, " "); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		i := 0
+		for i < len(rest) && rest[i] > ' ' && rest[i] != ':' && rest[i] != '"' && rest[i] != 0x7f {
+			i++
+		}
+		key := rest[:i]
+		if key == "" {
+			return nil, fmt.Errorf("missing key at %q in struct tag", rest)
+		}
+		if i+1 >= len(rest) || rest[i] != ':' || rest[i+1] != '"' {
+			return nil, fmt.Errorf("key %q must be followed by :\"value\" in struct tag", key)
+		}
+
+		end := skipQuoted(rest, i+1)
+		if end == -1 {
+			return nil, fmt.Errorf("unterminated value for key %q in struct tag", key)
+		}
+		value, err := strconv.Unquote(rest[i+1 : end+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for key %q in struct tag: %w", key, err)
+		}
+		if _, dup := pairs[key]; dup {
+			return nil, fmt.Errorf("duplicate key %q in struct tag", key)
+		}
+		pairs[key] = value
+		rest = rest[end+1:]
+	}
+	return pairs, nil
+}
+
+// Example usage:
+// parseStructTag(`json:"x" db:"y"`)         // {json: "x", db: "y"}
+// parseStructTag(`json:"name,omitempty"`)   // {json: "name,omitempty"}
+// parseStructTag(`desc:"two words"`)        // {desc: "two words"}
+// parseStructTag(`json:"x`)                 // error: unterminated value
+
+// ============================================================================
+// 4. IMPORT DECLARATIONS
+// ============================================================================
+// EBNF: ImportDecl = "import" ( ImportSpec | "(" { ImportSpec ";" } ")" ) .
+//       ImportSpec = [ "." | PackageName ] ImportPath .
+//       ImportPath = string_lit .
+//
+// parseImportSpec reads one spec, with or without the "import" keyword in
+// front, so both `import "fmt"` and the `"fmt"` line of a block work.
+// parseImportBlock handles the parenthesised form, one spec per line; a
+// comment may follow the closing parenthesis.
+
+type ImportSpec struct {
+	Name string // "", ".", "_", or an alias
+	Path string // unquoted
+}
+
+func parseImportSpec(s string) (ImportSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ImportSpec{}, errEmptyInput
+	}
+	if isKeywordPrefix(s, "import") {
+		s = strings.TrimSpace(s[len("import"):])
+	}
+	if s == "" {
+		return ImportSpec{}, fmt.Errorf("missing import path")
+	}
+
+	var spec ImportSpec
+	if s[0] != '"' && s[0] != '`' {
+		spec.Name = leadingIdentifier(s)
+		if strings.HasPrefix(s, ".") {
+			spec.Name = "."
+		}
+		if spec.Name == "" {
+			return ImportSpec{}, fmt.Errorf("invalid import spec %q", s)
+		}
+		if spec.Name != "." {
+			if err := validateIdentifier(spec.Name); err != nil || isKeyword(spec.Name) {
+				return ImportSpec{}, fmt.Errorf("invalid import name %q", spec.Name)
+			}
+		}
+		s = strings.TrimSpace(s[len(spec.Name):])
+	}
+
+	path, err := strconv.Unquote(s)
+	if err != nil {
+		return ImportSpec{}, fmt.Errorf("import path must be a single string literal, got %q", s)
+	}
+	if path == "" {
+		return ImportSpec{}, fmt.Errorf("empty import path")
+	}
+	spec.Path = path
+	return spec, nil
+}
+
+// ImportBlock is a parenthesised import declaration as a Node.
+type ImportBlock []ImportSpec
+
+func parseImportBlock(src string) ([]ImportSpec, error) {
+	s := strings.TrimSpace(src)
+	if s == "" {
+		return nil, errEmptyInput
+	}
+	if !isKeywordPrefix(s, "import") {
+		return nil, fmt.Errorf("not an import declaration")
+	}
+	s = strings.TrimSpace(s[len("import"):])
+	if !strings.HasPrefix(s, "(") {
+		return nil, fmt.Errorf("expected ( after import")
+	}
+
+	// Comments go first, so a ")" in one neither closes the block nor
+	// counts as text after it
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = StripLineComment(line)
+	}
+	s = strings.Join(lines, "\n")
+	closeIdx := matchingBracket(s, 0)
+	if closeIdx == -1 {
+		return nil, fmt.Errorf("unterminated import block: missing )")
+	}
+	if rest := strings.TrimSpace(s[closeIdx+1:]); rest != "" {
+		return nil, fmt.Errorf("unexpected %q after import block", rest)
+	}
+
+	specs := []ImportSpec{}
+	for n, line := range strings.Split(s[1:closeIdx], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		spec, err := parseImportSpec(line)
+		if err != nil {
+			return nil, fmt.Errorf("import block line %d: %w", n+1, err)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Example usage:
+// parseImportSpec(`import "fmt"`)           // {Path: "fmt"}
+// parseImportSpec(`str "strings"`)          // {Name: "str", Path: "strings"}
+// parseImportSpec(`. "math"`)               // {Name: ".", Path: "math"}
+// parseImportBlock("import (\n\t\"fmt\"\n\t\"os\"\n)")  // [{Path: fmt} {Path: os}]
+
+// ============================================================================
+// 5. VAR, CONST, AND TYPE DECLARATIONS
+// ============================================================================
+// EBNF: VarDecl   = "var" ( VarSpec | "(" { VarSpec ";" } ")" ) .
+//       VarSpec   = IdentifierList ( Type [ "=" ExpressionList ] | "=" ExpressionList ) .
+//       ConstDecl = "const" ( ConstSpec | "(" { ConstSpec ";" } ")" ) .
+//       ConstSpec = IdentifierList [ [ Type ] "=" ExpressionList ] .
+//       TypeDecl  = "type" ( TypeSpec | "(" { TypeSpec ";" } ")" ) .
+//       TypeSpec  = identifier [ TypeParameters ] [ "=" ] Type .
+//
+// The three declarations share one shape, so parseGenDecl reads the keyword
+// and the optional block and hands each spec to parseVarSpec,
+// parseConstSpec, or parseTypeDef. Specs in a block are separated by
+// newlines or semicolons; one may span lines while a bracket is open, as a
+// struct type does. A const spec without values repeats the previous one
+// (the iota idiom), so only the first spec of a const block needs them.
+// Struct and interface bodies are only checked for balanced braces.
+
+// DeclSpec is a ValueSpec or a TypeDef.
+type DeclSpec interface {
+	Node
+	declSpec()
+}
+
+type ValueSpec struct {
+	Names  []string
+	Type   string   // optional
+	Values []string // empty when omitted
+}
+
+type TypeDef struct {
+	Name       string
+	TypeParams []TypeParam // nil unless the type is generic
+	Alias      bool        // "type A = B"
+	Type       string
+}
+
+func (ValueSpec) declSpec() {}
+func (TypeDef) declSpec()   {}
+
+type GenDecl struct {
+	Keyword string // "var", "const", or "type"
+	Grouped bool   // written as a parenthesised block
+	Specs   []DeclSpec
+}
+
+func parseGenDecl(src string) (GenDecl, error) {
+	s := strings.TrimSpace(src)
+	if s == "" {
+		return GenDecl{}, errEmptyInput
+	}
+
+	decl := GenDecl{Keyword: leadingIdentifier(s)}
+	var parseSpec func(string) (DeclSpec, error)
+	switch decl.Keyword {
+	case "var":
+		parseSpec = func(s string) (DeclSpec, error) { return parseVarSpec(s) }
+	case "const":
+		parseSpec = func(s string) (DeclSpec, error) { return parseConstSpec(s) }
+	case "type":
+		parseSpec = func(s string) (DeclSpec, error) { return parseTypeDef(s) }
+	default:
+		return GenDecl{}, fmt.Errorf("expected var, const, or type declaration, found %q", decl.Keyword)
+	}
+	s = strings.TrimSpace(s[len(decl.Keyword):])
+
+	specs := []string{s}
+	if strings.HasPrefix(s, "(") {
+		if matchingBracket(s, 0) != len(s)-1 {
+			return GenDecl{}, fmt.Errorf("unterminated %s block: missing )", decl.Keyword)
+		}
+		var err error
+		if specs, err = splitDeclBlock(s[1 : len(s)-1]); err != nil {
+			return GenDecl{}, fmt.Errorf("invalid %s block: %w", decl.Keyword, err)
+		}
+		decl.Grouped = true
+	}
+
+	decl.Specs = []DeclSpec{}
+	for i, text := range specs {
+		spec, err := parseSpec(text)
+		if err != nil {
+			return GenDecl{}, fmt.Errorf("%s spec %d: %w", decl.Keyword, i+1, err)
+		}
+		if v, ok := spec.(ValueSpec); ok && decl.Keyword == "const" && i == 0 && len(v.Values) == 0 {
+			return GenDecl{}, fmt.Errorf("missing init expression for const %v", v.Names)
+		}
+		decl.Specs = append(decl.Specs, spec)
+	}
+	return decl, nil
+}
+
+// splitDeclBlock splits the body of a declaration block into specs,
+// dropping comments and blank lines.
+func splitDeclBlock(body string) ([]string, error) {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = StripLineComment(line)
+	}
+	lines, err := splitDelimitedList(strings.Join(lines, "\n"), '\n')
+	if err != nil {
+		return nil, err
+	}
+
+	specs := []string{}
+	for _, line := range lines {
+		parts, err := splitDelimitedList(line, ';')
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			if part != "" {
+				specs = append(specs, part)
+			}
+		}
+	}
+	return specs, nil
+}
+
+func parseVarSpec(s string) (ValueSpec, error) {
+	spec, err := parseValueSpec(s)
+	if err != nil {
+		return ValueSpec{}, err
+	}
+	if spec.Type == "" && len(spec.Values) == 0 {
+		return ValueSpec{}, fmt.Errorf("missing type or init expression for %v", spec.Names)
+	}
+	if err := checkValueCount(spec, true); err != nil {
+		return ValueSpec{}, err
+	}
+	return spec, nil
+}
+
+func parseConstSpec(s string) (ValueSpec, error) {
+	spec, err := parseValueSpec(s)
+	if err != nil {
+		return ValueSpec{}, err
+	}
+	if spec.Type != "" && len(spec.Values) == 0 {
+		return ValueSpec{}, fmt.Errorf("missing init expression for const %v", spec.Names)
+	}
+	// constants are never multi-valued, so "const a, b = f()" is a mismatch
+	if err := checkValueCount(spec, false); err != nil {
+		return ValueSpec{}, err
+	}
+	return spec, nil
+}
+
+// parseValueSpec reads the IdentifierList [ Type ] [ "=" ExpressionList ]
+// shape that VarSpec and ConstSpec share; each adds its own rule for which
+// parts may be left out.
+func parseValueSpec(s string) (ValueSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ValueSpec{}, errEmptyInput
+	}
+
+	lhs, rhs := s, ""
+	start, end := assignOpIndex(s)
+	hasValues := start != -1
+	if hasValues {
+		if s[start:end] != "=" {
+			return ValueSpec{}, fmt.Errorf("unexpected %s in declaration %q", s[start:end], s)
+		}
+		lhs, rhs = strings.TrimSpace(s[:start]), strings.TrimSpace(s[end:])
+	}
+
+	// "a, b int": the type follows the last name
+	parts, err := splitDelimitedList(lhs, ',')
+	if err != nil {
+		return ValueSpec{}, err
+	}
+	last := parts[len(parts)-1]
+	name := leadingIdentifier(last)
+	spec := ValueSpec{Type: strings.TrimSpace(last[len(name):])}
+	if spec.Names, err = parseIdentifierList(strings.Join(append(parts[:len(parts)-1], name), ",")); err != nil {
+		return ValueSpec{}, err
+	}
+
+	if spec.Type != "" {
+		if err := validateDeclType(spec.Type); err != nil {
+			return ValueSpec{}, fmt.Errorf("invalid type %q: %w", spec.Type, err)
+		}
+	}
+
+	spec.Values = []string{}
+	if hasValues {
+		if spec.Values, err = splitExpressionList(rhs); err != nil {
+			return ValueSpec{}, err
+		}
+	}
+	return spec, nil
+}
+
+// checkValueCount reports a spec whose values don't pair up with its
+// names. With multiValued, one value may stand for all of them, as
+// "var a, b = f()" does, since a call can return several results.
+func checkValueCount(spec ValueSpec, multiValued bool) error {
+	n := len(spec.Values)
+	if n == 0 || n == len(spec.Names) || (multiValued && n == 1) {
+		return nil
+	}
+	return fmt.Errorf("assignment mismatch: %d names but %d values", len(spec.Names), n)
+}
+
+func parseTypeDef(s string) (TypeDef, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return TypeDef{}, errEmptyInput
+	}
+
+	def := TypeDef{Name: leadingIdentifier(s)}
+	if def.Name == "" || isKeyword(def.Name) {
+		return TypeDef{}, fmt.Errorf("invalid type name in %q", s)
+	}
+	rest := strings.TrimSpace(s[len(def.Name):])
+
+	// "[T any] ..." declares type parameters, "[4]int" is an array type
+	if strings.HasPrefix(rest, "[") {
+		if closeIdx := matchingBracket(rest, 0); closeIdx != -1 {
+			if tps, err := parseTypeParams(rest[:closeIdx+1]); err == nil {
+				def.TypeParams = tps
+				rest = strings.TrimSpace(rest[closeIdx+1:])
+			}
+		}
+	}
+	if typ, ok := strings.CutPrefix(rest, "="); ok {
+		def.Alias, rest = true, strings.TrimSpace(typ)
+	}
+
+	if rest == "" {
+		return TypeDef{}, fmt.Errorf("missing type for %s", def.Name)
+	}
+	if err := validateDeclType(rest); err != nil {
+		return TypeDef{}, fmt.Errorf("invalid type for %s: %w", def.Name, err)
+	}
+	def.Type = rest
+	return def, nil
+}
+
+// validateDeclType is parseTypeSpec plus the struct and interface types a
+// declaration is usually for.
+func validateDeclType(s string) error {
+	for _, kw := range []string{"struct", "interface"} {
+		if isKeywordPrefix(s, kw) {
+			body := strings.TrimSpace(s[len(kw):])
+			if body == "" || body[0] != '{' || matchingBracket(body, 0) != len(body)-1 {
+				return fmt.Errorf("invalid %s type %q", kw, s)
+			}
+			return nil
+		}
+	}
+	_, err := parseTypeSpec(s)
+	return err
+}
+
+// Example usage:
+// parseGenDecl("var x int = 5")                 // {var, [{[x] int [5]}]}
+// parseGenDecl("const (\n\tA = iota\n\tB\n)")  // {const, grouped, [{[A] [iota]} {[B] []}]}
+// parseGenDecl("type List[T any] []T")          // {type, [{List [{[T] any}] []T}]}
+// parseGenDecl("var x")                         // error: missing type or init expression
+// parseGenDecl("type Point struct{ X, Y int }")
+// // {type, [{Name: Point, Type: struct{ X, Y int }}]}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStructField(t *testing.T) {
+	tests := []struct {
+		input string
+		want  StructField
+	}{
+		{"Name string", StructField{Names: []string{"Name"}, Type: "string"}},
+		{"Age int `json:\"age\"`", StructField{Names: []string{"Age"}, Type: "int", Tag: `json:"age"`}},
+		{"io.Reader", StructField{Type: "io.Reader"}},
+		{"*Node", StructField{Type: "*Node"}},
+		{"X, Y int", StructField{Names: []string{"X", "Y"}, Type: "int"}},
+		{"Handler func(a, b int) error", StructField{Names: []string{"Handler"}, Type: "func(a, b int) error"}},
+		{`Age int "json:\"age\""`, StructField{Names: []string{"Age"}, Type: "int", Tag: `json:"age"`}},
+		{"*Node `xml:\"node\"`", StructField{Type: "*Node", Tag: `xml:"node"`}},
+		{"M map[string][]int", StructField{Names: []string{"M"}, Type: "map[string][]int"}},
+		{"Inner struct{ X int } `json:\"inner\"`", StructField{Names: []string{"Inner"}, Type: "struct{ X int }", Tag: `json:"inner"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseStructField(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"X, Y", "", "1x int", "Name int `json", "X, 2 int",
+		"type int",             // keyword as a name
+		"X, func int",          // keyword as a name
+		"X !!!",                // not a type
+		"X int extra",          // not a type
+		`Age int "json:"age""`, // malformed interpreted tag
+		`X int "a" "b"`,        // text after the tag
+		`X int "unterminated`,  // unterminated tag
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseStructField(input); err == nil {
+				t.Errorf("parseStructField(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParsePackageClause(t *testing.T) {
+	for input, want := range map[string]string{
+		"package main":      "main",
+		"package my_pkg":    "my_pkg",
+		"  package   ebnf ": "ebnf",
+	} {
+		got, err := parsePackageClause(input)
+		if err != nil {
+			t.Errorf("parsePackageClause(%q) unexpected error: %v", input, err)
+		}
+		if got != want {
+			t.Errorf("parsePackageClause(%q) = %q want %q", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"package", "package 123", "import \"fmt\"", "packagemain", "package _", "package main extra", "package func"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parsePackageClause(input); err == nil {
+				t.Errorf("parsePackageClause(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseStructTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want map[string]string
+	}{
+		{``, map[string]string{}},
+		{`json:"x"`, map[string]string{"json": "x"}},
+		{`json:"x" db:"y"`, map[string]string{"json": "x", "db": "y"}},
+		{`json:"name,omitempty" xml:"n"`, map[string]string{"json": "name,omitempty", "xml": "n"}},
+		{`desc:"two words"  other:""`, map[string]string{"desc": "two words", "other": ""}},
+		{`q:"say \"hi\""`, map[string]string{"q": `say "hi"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := parseStructTag(tt.tag)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, tag := range []string{
+		`json:"x`,           // missing closing quote
+		`json:x`,            // unquoted value
+		`json`,              // no value at all
+		`:"x"`,              // no key
+		`json: "x"`,         // space after the colon
+		`json:"x" json:"y"`, // duplicate key
+		`q:"\z"`,            // bad escape
+	} {
+		t.Run("error "+tag, func(t *testing.T) {
+			if _, err := parseStructTag(tag); err == nil {
+				t.Errorf("parseStructTag(%q) expected an error", tag)
+			}
+		})
+	}
+}
+
+func TestParseImportSpec(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ImportSpec
+	}{
+		{`import "fmt"`, ImportSpec{Path: "fmt"}},
+		{`"os"`, ImportSpec{Path: "os"}},
+		{`str "strings"`, ImportSpec{Name: "str", Path: "strings"}},
+		{`import _ "embed"`, ImportSpec{Name: "_", Path: "embed"}},
+		{`. "math"`, ImportSpec{Name: ".", Path: "math"}},
+		{"`net/http`", ImportSpec{Path: "net/http"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseImportSpec(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"import", `""`, "fmt", `"fmt`, `1x "fmt"`, `func "fmt"`, `a "b" "c"`} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseImportSpec(input); err == nil {
+				t.Errorf("parseImportSpec(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseImportBlock(t *testing.T) {
+	t.Run("two imports", func(t *testing.T) {
+		got, err := parseImportBlock("import (\n\t\"fmt\"\n\t\"os\"\n)")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []ImportSpec{{Path: "fmt"}, {Path: "os"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+	t.Run("aliases, blank lines, and comments", func(t *testing.T) {
+		src := "import (\n\tstr \"strings\"\n\n\t_ \"embed\" // for go:embed\n\t. \"math\"\n)"
+		got, err := parseImportBlock(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []ImportSpec{{Name: "str", Path: "strings"}, {Name: "_", Path: "embed"}, {Name: ".", Path: "math"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+	t.Run("comment after the block", func(t *testing.T) {
+		for _, src := range []string{"import (\n\"fmt\"\n) // done", "import (\n\"fmt\" // see (a)\n)"} {
+			got, err := parseImportBlock(src)
+			if err != nil {
+				t.Fatalf("parseImportBlock(%q): unexpected error: %v", src, err)
+			}
+			if want := []ImportSpec{{Path: "fmt"}}; !reflect.DeepEqual(got, want) {
+				t.Errorf("parseImportBlock(%q) got %+v want %+v", src, got, want)
+			}
+		}
+	})
+	t.Run("empty block", func(t *testing.T) {
+		got, err := parseImportBlock("import ()")
+		if err != nil || len(got) != 0 {
+			t.Errorf("got %+v, %v want no specs", got, err)
+		}
+	})
+
+	for _, src := range []string{
+		"import (\n\t\"fmt\"\n", // unterminated
+		"import \"fmt\"",        // not a block
+		"package main",
+		"import (\n\tfmt\n)",          // unquoted path
+		"import (\n\"fmt\"\n) \"os\"", // text after the block
+		"import (\n\"fmt\" // )\n",    // ")" only in a comment
+	} {
+		t.Run("error "+src, func(t *testing.T) {
+			if _, err := parseImportBlock(src); err == nil {
+				t.Errorf("parseImportBlock(%q) expected an error", src)
+			}
+		})
+	}
+}
+
+func TestParseGenDecl(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  GenDecl
+	}{
+		{"single var", "var x int = 5", GenDecl{Keyword: "var", Specs: []DeclSpec{
+			ValueSpec{Names: []string{"x"}, Type: "int", Values: []string{"5"}},
+		}}},
+		{"single var without type", "var a, b = f(1, 2), 3", GenDecl{Keyword: "var", Specs: []DeclSpec{
+			ValueSpec{Names: []string{"a", "b"}, Values: []string{"f(1, 2)", "3"}},
+		}}},
+		{"multi-valued var", "var a, b = f()", GenDecl{Keyword: "var", Specs: []DeclSpec{
+			ValueSpec{Names: []string{"a", "b"}, Values: []string{"f()"}},
+		}}},
+		{"var block", "var (\n\tn int\n\terr error // reported later\n\n\tf func(a, b int) = nil\n)", GenDecl{
+			Keyword: "var", Grouped: true, Specs: []DeclSpec{
+				ValueSpec{Names: []string{"n"}, Type: "int", Values: []string{}},
+				ValueSpec{Names: []string{"err"}, Type: "error", Values: []string{}},
+				ValueSpec{Names: []string{"f"}, Type: "func(a, b int)", Values: []string{"nil"}},
+			},
+		}},
+		{"single const", "const Pi = 3.14", GenDecl{Keyword: "const", Specs: []DeclSpec{
+			ValueSpec{Names: []string{"Pi"}, Values: []string{"3.14"}},
+		}}},
+		{"const block", "const (\n\tA Weekday = iota\n\tB\n\tC; D\n)", GenDecl{
+			Keyword: "const", Grouped: true, Specs: []DeclSpec{
+				ValueSpec{Names: []string{"A"}, Type: "Weekday", Values: []string{"iota"}},
+				ValueSpec{Names: []string{"B"}, Values: []string{}},
+				ValueSpec{Names: []string{"C"}, Values: []string{}},
+				ValueSpec{Names: []string{"D"}, Values: []string{}},
+			},
+		}},
+		{"single type", "type ID string", GenDecl{Keyword: "type", Specs: []DeclSpec{
+			TypeDef{Name: "ID", Type: "string"},
+		}}},
+		{"type block", "type (\n\tPoint struct {\n\t\tX, Y int\n\t}\n\tGrid [4][4]Point\n\tList[T any] []T\n\tAlias = Point\n)", GenDecl{
+			Keyword: "type", Grouped: true, Specs: []DeclSpec{
+				TypeDef{Name: "Point", Type: "struct {\n\t\tX, Y int\n\t}"},
+				TypeDef{Name: "Grid", Type: "[4][4]Point"},
+				TypeDef{Name: "List", TypeParams: []TypeParam{{Names: []string{"T"}, Constraint: "any"}}, Type: "[]T"},
+				TypeDef{Name: "Alias", Alias: true, Type: "Point"},
+			},
+		}},
+		{"empty block", "var ()", GenDecl{Keyword: "var", Grouped: true, Specs: []DeclSpec{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGenDecl(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"func f()",                   // not a GenDecl keyword
+		"var x",                      // no type or value
+		"var x, y = 1, 2, 3",         // count mismatch
+		"var x, y, z = 1, 2",         // count mismatch
+		"const x, y = f()",           // constants are single-valued
+		"var x += 1",                 // not "="
+		"var 1x int",                 // invalid name
+		"var x 1y",                   // invalid type
+		"var (\n\tx int\n",           // unterminated block
+		"const x int",                // typed const without value
+		"const (\n\tA\n\tB = 1\n)",   // first const spec without value
+		"type",                       // no spec
+		"type T",                     // no type
+		"type T struct",              // struct without body
+		"type (\n\tA int\n\tfunc\n)", // keyword as a type name
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseGenDecl(input); err == nil {
+				t.Errorf("parseGenDecl(%q) expected an error", input)
+			}
+		})
+	}
+}
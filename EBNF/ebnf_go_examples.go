@@ -1,9 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"math/big"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // ============================================================================
@@ -12,6 +18,39 @@ import (
 //
 // This file demonstrates how EBNF grammar rules translate to Go code
 // Based on: https://go.dev/ref/spec#Notation
+//
+// Empty input: the is* predicates answer for "" exactly as the grammar does,
+// so isDigits("") is true (Digits = { Digit } matches nothing) and
+// isValidIdentifier("") is false. Parsers that return an error return
+// errEmptyInput for "" or all-whitespace input, checkable with errors.Is.
+// The exceptions are list-like results where empty is a valid answer:
+// splitDelimitedList, Tokenize, parseStructTag, parseParamList, and
+// Grammar.Match.
+
+var errEmptyInput = errors.New("empty input")
+
+// ParseError is a parse failure at a known place in the input, for callers
+// that want to point at it rather than just print it. Pos is a rune index
+// into the input as passed to the parser, so it can be used to draw a caret
+// under the offending character. Err is the underlying cause, if any.
+type ParseError struct {
+	Message string
+	Pos     int
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// newParseError formats its message like fmt.Errorf, so a %w verb sets
+// Err.
+func newParseError(pos int, format string, args ...any) *ParseError {
+	err := fmt.Errorf(format, args...)
+	return &ParseError{Message: err.Error(), Pos: pos, Err: errors.Unwrap(err)}
+}
 
 // ============================================================================
 // 1. ALTERNATION (|) - Choose ONE option
@@ -19,13 +58,76 @@ import (
 // EBNF: Boolean = "true" | "false" .
 
 func isBoolean(s string) bool {
-	return s == "true" || s == "false"
+	_, ok := MatchAlternation(s, []string{"true", "false"})
+	return ok
+}
+
+// MatchAlternation reports which alternative s matches exactly: the index of
+// the first equal option, or -1 and false if none does.
+func MatchAlternation(s string, options []string) (index int, ok bool) {
+	for i, option := range options {
+		if s == option {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// longestMatch is alternation by maximal munch: of the candidates that s
+// starts with, it returns the longest, so "==" wins over "=" whatever the
+// order of the list. Between equal lengths the first listed wins. Empty
+// candidates never match.
+func longestMatch(s string, candidates []string) (match string, ok bool) {
+	for _, c := range candidates {
+		if len(c) > len(match) && strings.HasPrefix(s, c) {
+			match, ok = c, true
+		}
+	}
+	return match, ok
 }
 
 // Example usage:
 // isBoolean("true")   // true
 // isBoolean("false")  // true
 // isBoolean("maybe")  // false
+// MatchAlternation("false", []string{"true", "false"})  // 1, true
+// longestMatch("== b", []string{"=", "=="})             // "==", true
+// longestMatch("x", []string{"=", "=="})                // "", false
+
+// ParseBool is the lenient counterpart of isBoolean. It accepts exactly the
+// lowercase, Title-case, and UPPERCASE spellings of "true" and "false" and
+// rejects everything else ("yes", "1", "t", "tRuE", ...). isBoolean stays
+// strict for spec-accurate checks.
+func ParseBool(s string) (value bool, ok bool) {
+	switch s {
+	case "true", "True", "TRUE":
+		return true, true
+	case "false", "False", "FALSE":
+		return false, true
+	}
+	return false, false
+}
+
+// ParseBoolStrict accepts what isBoolean does, only "true" and "false",
+// and says why anything else was rejected, for config validation that has
+// to report the bad value.
+func ParseBoolStrict(s string) (bool, error) {
+	if strings.TrimSpace(s) == "" {
+		return false, errEmptyInput
+	}
+	i, ok := MatchAlternation(s, []string{"true", "false"})
+	if !ok {
+		return false, fmt.Errorf("invalid boolean %q", s)
+	}
+	return i == 0, nil
+}
+
+// Example usage:
+// ParseBool("True")   // true, true
+// ParseBool("FALSE")  // false, true
+// ParseBool("yes")    // false, false
+// ParseBoolStrict("false")  // false, nil
+// ParseBoolStrict("maybe")  // error: invalid boolean "maybe"
 
 // ============================================================================
 // 2. GROUPING () - Group expressions together
@@ -39,59 +141,168 @@ type SignedNumber struct {
 }
 
 func parseSignedNumber(s string) (SignedNumber, error) {
-	s = strings.TrimSpace(s)
+	if strings.TrimSpace(s) == "" {
+		return SignedNumber{}, errEmptyInput
+	}
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	lead := utf8.RuneCountInString(s[:len(s)-len(trimmed)]) // for positions in s
+	sc := NewScanner(strings.TrimSpace(trimmed))
 	sn := SignedNumber{}
 
 	// Optional sign (grouping with alternation)
-	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
-		sn.Sign = string(s[0])
-		s = s[1:]
+	if c := sc.Peek(); c == '+' || c == '-' {
+		sn.Sign = string(sc.Next())
+
+		// A sign must be followed by a Number
+		if sc.Eof() {
+			return sn, newParseError(lead+sc.Pos(), "no digits after sign %q", sn.Sign)
+		}
+		if c := sc.Peek(); !isDigit(c) {
+			return sn, newParseError(lead+sc.Pos(), "expected digit after sign %q, found %q", sn.Sign, c)
+		}
 	} else {
 		sn.Sign = "+" // default positive
 	}
 
 	// Parse number
 	var num int
-	_, err := fmt.Sscanf(s, "%d", &num)
+	_, err := fmt.Sscanf(sc.Rest(), "%d", &num)
 	if err != nil {
-		return sn, err
+		return sn, newParseError(lead+sc.Pos(), "invalid number %q: %v", sc.Rest(), err)
 	}
 	sn.Number = num
 	return sn, nil
 }
 
+// String returns the canonical form, always with an explicit sign: "+42".
+func (sn SignedNumber) String() string {
+	sign := sn.Sign
+	if sign == "" {
+		sign = "+"
+	}
+	return fmt.Sprintf("%s%d", sign, sn.Number)
+}
+
+// Value returns the number as a signed int. An empty Sign counts as "+".
+func (sn SignedNumber) Value() int {
+	if sn.Sign == "-" {
+		return -sn.Number
+	}
+	return sn.Number
+}
+
+// Add returns sn + other in the same sign-and-magnitude form.
+func (sn SignedNumber) Add(other SignedNumber) SignedNumber {
+	return signedNumberOf(sn.Value() + other.Value())
+}
+
+// Negate returns -sn. Zero stays "+0".
+func (sn SignedNumber) Negate() SignedNumber {
+	return signedNumberOf(-sn.Value())
+}
+
+// Normalize returns the canonical form of sn: Sign is always "+" or "-",
+// zero is always "+0", and Number is never negative. It is idempotent, and
+// two SignedNumbers with the same Value normalize to equal structs.
+func (sn SignedNumber) Normalize() SignedNumber {
+	return signedNumberOf(sn.Value())
+}
+
+// signedNumberOf splits v into the Sign and Number parseSignedNumber would
+// give for its String.
+func signedNumberOf(v int) SignedNumber {
+	if v < 0 {
+		return SignedNumber{Sign: "-", Number: -v}
+	}
+	return SignedNumber{Sign: "+", Number: v}
+}
+
 // Example usage:
 // parseSignedNumber("+42")   // {"+", 42}
 // parseSignedNumber("-15")   // {"-", 15}
 // parseSignedNumber("99")    // {"+", 99}
+// parseSignedNumber("+")     // error: no digits after sign "+"
+// sn, _ := parseSignedNumber("-15")
+// sn.Value()                        // -15
+// sn.Add(SignedNumber{"+", 20})     // {"+", 5}
+// sn.Negate()                       // {"+", 15}
+// SignedNumber{"-", 0}.Normalize()  // {"+", 0}
 
 // ============================================================================
 // 3. OPTION [] - Zero or one occurrence (optional)
 // EBNF: FileExtension = [ "." identifier ] .
 
 type File struct {
+	Dir       string // optional: leading directory path, without trailing "/"
 	Name      string
 	Extension string // optional
 }
 
 func parseFilename(filename string) File {
-	parts := strings.Split(filename, ".")
+	return parseFilenameWithOptions(filename, false)
+}
 
-	file := File{
-		Name:      parts[0],
-		Extension: "", // no extension by default
+// parseFilenameWithOptions is parseFilename with the extension lowercased
+// when lowerExt is set, for matching "IMAGE.JPG" against "jpg". Dir and
+// Name keep their case either way.
+func parseFilenameWithOptions(filename string, lowerExt bool) File {
+	file := File{}
+
+	// Optional directory: everything up to the last "/"
+	if slash := strings.LastIndex(filename, "/"); slash != -1 {
+		file.Dir = filename[:slash]
+		if file.Dir == "" {
+			file.Dir = "/" // absolute path at the root
+		}
+		filename = filename[slash+1:]
 	}
 
-	if len(parts) > 1 {
-		file.Extension = parts[1] // optional extension
+	// Optional extension: after the last ".", so "a.tar.gz" has "gz"
+	file.Name = filename
+	if dot := strings.LastIndex(filename, "."); dot != -1 {
+		file.Name, file.Extension = filename[:dot], filename[dot+1:]
+	}
+	if lowerExt {
+		file.Extension = strings.ToLower(file.Extension)
 	}
 
 	return file
 }
 
+// parseFilenameFull is parseFilename for names with several extensions: it
+// returns the base name, without any directory, and every dot-separated
+// suffix in order, so "archive.tar.gz" is "archive" and [tar gz].
+func parseFilenameFull(filename string) (name string, extensions []string) {
+	if slash := strings.LastIndex(filename, "/"); slash != -1 {
+		filename = filename[slash+1:]
+	}
+	parts := strings.Split(filename, ".")
+	return parts[0], parts[1:]
+}
+
+// String rebuilds the path: "src/main.go", "README", "/init.rc".
+func (f File) String() string {
+	name := f.Name
+	if f.Extension != "" {
+		name += "." + f.Extension
+	}
+	switch f.Dir {
+	case "":
+		return name
+	case "/":
+		return "/" + name
+	}
+	return f.Dir + "/" + name
+}
+
 // Example usage:
 // parseFilename("document.txt")  // {Name: "document", Extension: "txt"}
 // parseFilename("README")        // {Name: "README", Extension: ""}
+// parseFilename("src/main.go")   // {Dir: "src", Name: "main", Extension: "go"}
+// parseFilename("/etc/hosts")    // {Dir: "/etc", Name: "hosts", Extension: ""}
+// parseFilename("a.tar.gz")      // {Name: "a.tar", Extension: "gz"}
+// parseFilenameFull("archive.tar.gz")  // "archive", [tar gz]
+// parseFilenameWithOptions("IMAGE.JPG", true)  // {Name: "IMAGE", Extension: "jpg"}
 
 // ============================================================================
 // 4. REPETITION {} - Zero or more occurrences
@@ -99,9 +310,19 @@ func parseFilename(filename string) File {
 //       Digit = "0" … "9" .
 
 func isDigits(s string) bool {
-	// Match zero or more digits
-	matched, _ := regexp.MatchString(`^\d*$`, s)
-	return matched
+	return matchesDigitSet(s, "0123456789")
+}
+
+// matchesDigitSet is isDigits for any digit set: zero or more runes, each
+// one of digits. The set is taken literally, so hex in both cases needs
+// "0123456789abcdefABCDEF".
+func matchesDigitSet(s, digits string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune(digits, c) {
+			return false
+		}
+	}
+	return true
 }
 
 // Example usage:
@@ -109,6 +330,9 @@ func isDigits(s string) bool {
 // isDigits("5")     // true (one digit)
 // isDigits("12345") // true (many digits)
 // isDigits("12a45") // false
+// matchesDigitSet("1010", "01")              // true (binary)
+// matchesDigitSet("FF", "0123456789ABCDEF")  // true (uppercase hex)
+// matchesDigitSet("ff", "0123456789ABCDEF")  // false
 
 // ============================================================================
 // 5. RANGE … - Set of characters
@@ -131,37 +355,102 @@ func isUpperLetter(c rune) bool {
 	return c >= 'A' && c <= 'Z'
 }
 
+// EBNF: HexDigit = "0" … "9" | "A" … "F" | "a" … "f" .
+func isHexDigit(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// EBNF: OctalDigit = "0" … "7" .
+func isOctalDigit(c rune) bool {
+	return c >= '0' && c <= '7'
+}
+
+// EBNF: BinaryDigit = "0" | "1" .
+func isBinaryDigit(c rune) bool {
+	return c == '0' || c == '1'
+}
+
 // Example usage:
 // isDigit('5')      // true
 // isLetter('A')     // true
 // isLetter('z')     // true
 // isLetter('1')     // false
+// isHexDigit('f')   // true
+// isHexDigit('g')   // false
+// isOctalDigit('8') // false
+// isBinaryDigit('1') // true
 
 // ============================================================================
 // 6. COMPLETE EXAMPLE - Identifier
 // ============================================================================
-// EBNF: Identifier = letter { letter | unicode_digit | "_" } .
-//       letter = "a"…"z" | "A"…"Z" | "_" .
+// EBNF: Identifier = letter { letter | unicode_digit } .
+//       letter = unicode_letter | "_" .
+//
+// unicode_letter and unicode_digit are Unicode classes L and Nd, so "café"
+// and "日本" are identifiers but "１２" (fullwidth digits) can't start one.
+// Error positions count runes, not bytes: the "-" in "café-x" is at 4.
 
 func isValidIdentifier(s string) bool {
+	return validateIdentifier(s) == nil
+}
+
+// validateIdentifier is isValidIdentifier with a reason: it returns nil for a
+// valid identifier and an error describing the first violation otherwise.
+func validateIdentifier(s string) error {
 	if len(s) == 0 {
-		return false
+		return errEmptyInput
 	}
 
 	// First character must be letter or underscore
-	firstChar := rune(s[0])
-	if !isLetter(firstChar) && firstChar != '_' {
-		return false
+	sc := NewScanner(s)
+	if !isIdentLetter(sc.Next()) {
+		return fmt.Errorf("identifier %q must start with a letter or underscore", s)
 	}
 
 	// Remaining characters: letter, digit, or underscore
-	for _, c := range s[1:] {
-		if !isLetter(c) && !isDigit(c) && c != '_' {
-			return false
+	for !sc.Eof() {
+		pos := sc.Pos()
+		if c := sc.Next(); !isIdentLetter(c) && !isUnicodeDigit(c) {
+			return fmt.Errorf("invalid character %q at position %d in identifier %q", c, pos, s)
 		}
 	}
 
-	return true
+	return nil
+}
+
+// isIdentLetter is the spec's letter: unicode_letter | "_".
+func isIdentLetter(c rune) bool {
+	return c == '_' || unicode.IsLetter(c)
+}
+
+// isUnicodeDigit is the spec's unicode_digit, the Nd (decimal digit) class.
+func isUnicodeDigit(c rune) bool {
+	return unicode.Is(unicode.Nd, c)
+}
+
+// ValidateIdentifiers runs validateIdentifier over a batch of names and
+// returns only the invalid ones, keyed by name, so everything can be reported
+// at once. A nil or empty result means every name is valid.
+func ValidateIdentifiers(names []string) map[string]error {
+	invalid := map[string]error{}
+	for _, name := range names {
+		if err := validateIdentifier(name); err != nil {
+			invalid[name] = err
+		}
+	}
+	return invalid
+}
+
+// NormalizeIdentifier trims the surrounding whitespace that identifiers
+// often pick up when split out of a declaration.
+func NormalizeIdentifier(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// IsValidIdentifierLoose is isValidIdentifier after NormalizeIdentifier, so
+// " name " is accepted. Whitespace inside the identifier is still invalid.
+func IsValidIdentifierLoose(s string) bool {
+	return isValidIdentifier(NormalizeIdentifier(s))
 }
 
 // Example usage:
@@ -171,19 +460,59 @@ func isValidIdentifier(s string) bool {
 // isValidIdentifier("MY_CONST")   // true
 // isValidIdentifier("123var")     // false (starts with digit)
 // isValidIdentifier("my-var")     // false (contains hyphen)
+//
+// isValidIdentifier("café")       // true (Unicode letters are letters)
+// validateIdentifier("my-var")    // invalid character '-' at position 2 ...
+// validateIdentifier("café-x")    // invalid character '-' at position 4 ...
+// ValidateIdentifiers([]string{"ok", "1bad"}) // map["1bad": error]
+// isValidIdentifier(" name")      // false (strict)
+// IsValidIdentifierLoose(" name") // true
 
 // ============================================================================
 // 7. COMPLETE EXAMPLE - Integer Literal
 // ============================================================================
-// EBNF: IntLit = DecimalLit | HexLit .
+// EBNF: IntLit = DecimalLit | BinaryLit | OctalLit | HexLit .
 //       DecimalLit = ( "1"…"9" ) { DecimalDigit } | "0" .
+//       BinaryLit = "0" ( "b" | "B" ) BinaryDigit { BinaryDigit } .
+//       OctalLit = "0" ( "o" | "O" ) OctalDigit { OctalDigit } .
 //       HexLit = "0" ( "x" | "X" ) HexDigit { HexDigit } .
+//
+// Go also accepts the legacy octal form "0" OctalDigit { OctalDigit }
+// ("0777"), which is easy to write by accident when zero-padding. Two modes:
+//   - isValidIntegerStrict: DecimalLit, BinaryLit, OctalLit ("0o777"),
+//     HexLit. "0777" and "007" are rejected. isValidInteger uses this mode.
+//   - isValidIntegerLenient: everything strict accepts plus legacy octal, so
+//     "0777" and "00" are valid. "09" is still invalid, as in Go.
+//
+// Both modes allow "_" between digits, as in "1_000" or "0x_FF_FF".
 
 func isValidInteger(s string) bool {
+	return isValidIntegerStrict(s)
+}
+
+func isValidIntegerLenient(s string) bool {
+	digits, ok := stripDigitSeparators(s)
+	return ok && (isValidIntegerStrict(s) || isValidLegacyOctal(digits))
+}
+
+func isValidIntegerStrict(s string) bool {
+	s, ok := stripDigitSeparators(s)
+	if !ok {
+		return false
+	}
+
 	// Try decimal
 	if isValidDecimal(s) {
 		return true
 	}
+	// Try binary
+	if isValidBinary(s) {
+		return true
+	}
+	// Try octal
+	if isValidOctal(s) {
+		return true
+	}
 	// Try hex
 	if isValidHex(s) {
 		return true
@@ -191,24 +520,105 @@ func isValidInteger(s string) bool {
 	return false
 }
 
-func isValidDecimal(s string) bool {
-	if len(s) == 0 {
-		return false
+// stripDigitSeparators removes the underscores Go allows in integer
+// literals ("1_000", "0x_FF") and reports whether they were well placed:
+// each must sit between two digits, or between the base prefix and a digit.
+// The prefix is read first so its letter never counts as a digit: "0_b1"
+// and "0_x1" are rejected, since without a "0x" prefix a-f aren't digits.
+// The digits themselves are left for the validators to check.
+func stripDigitSeparators(s string) (string, bool) {
+	if !strings.Contains(s, "_") {
+		return s, true
 	}
+	prefix, isDigitOf := 0, isDigit
+	if len(s) > 1 && s[0] == '0' && strings.ContainsRune("bBoOxX", rune(s[1])) {
+		prefix = 2
+		if s[1] == 'x' || s[1] == 'X' {
+			isDigitOf = isHexDigit
+		}
+	}
+	for i := range len(s) {
+		if s[i] != '_' {
+			continue
+		}
+		afterPrefix := prefix > 0 && i == prefix
+		if i == 0 || i == len(s)-1 || !isDigitOf(rune(s[i+1])) || (!isDigitOf(rune(s[i-1])) && !afterPrefix) {
+			return "", false
+		}
+	}
+	return strings.ReplaceAll(s, "_", ""), true
+}
 
+func isValidDecimal(s string) bool {
 	// Single "0" is valid
 	if s == "0" {
 		return true
 	}
 
-	// First digit must be 1-9
-	if s[0] < '1' || s[0] > '9' {
+	// First digit must be 1-9 (Next returns eof on empty input)
+	sc := NewScanner(s)
+	if c := sc.Next(); c < '1' || c > '9' {
 		return false
 	}
 
 	// Remaining digits must be 0-9
+	sc.AcceptWhile(isDigit)
+	return sc.Eof()
+}
+
+// isValidDecimalPadded is isValidDecimal for zero-padded data such as
+// "007": any non-empty run of digits, read as plain decimal rather than
+// the octal Go would make of it.
+func isValidDecimalPadded(s string) bool {
+	return s != "" && isDigits(s)
+}
+
+func isValidBinary(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+
+	// Must start with 0b or 0B
+	if s[0] != '0' || (s[1] != 'b' && s[1] != 'B') {
+		return false
+	}
+
+	// Rest must be binary digits (0-1)
+	for _, c := range s[2:] {
+		if !isBinaryDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isValidOctal(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+
+	// Must start with 0o or 0O
+	if s[0] != '0' || (s[1] != 'o' && s[1] != 'O') {
+		return false
+	}
+
+	// Rest must be octal digits (0-7)
+	for _, c := range s[2:] {
+		if !isOctalDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isValidLegacyOctal(s string) bool {
+	if len(s) < 2 || s[0] != '0' {
+		return false
+	}
+
+	// Rest must be octal digits (0-7)
 	for _, c := range s[1:] {
-		if c < '0' || c > '9' {
+		if !isOctalDigit(c) {
 			return false
 		}
 	}
@@ -227,19 +637,109 @@ func isValidHex(s string) bool {
 
 	// Rest must be hex digits (0-9, a-f, A-F)
 	for _, c := range s[2:] {
-		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+		if !isHexDigit(c) {
 			return false
 		}
 	}
 	return true
 }
 
+// isValidHexWithCase is isValidHex for style checks that want one letter
+// case throughout: with requireLower the digits a-f must be lowercase,
+// otherwise A-F must be uppercase. The "0x"/"0X" prefix is not checked.
+func isValidHexWithCase(s string, requireLower bool) bool {
+	if !isValidHex(s) {
+		return false
+	}
+	for _, c := range s[2:] {
+		if (requireLower && isUpperLetter(c)) || (!requireLower && isLowerLetter(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseIntegerValue converts an integer literal to its value. It accepts
+// exactly what isValidInteger does, underscores included, so legacy octal
+// ("0755") is an error here too; values above math.MaxInt64 overflow.
+func ParseIntegerValue(s string) (int64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, errEmptyInput
+	}
+	if !isValidInteger(s) {
+		return 0, fmt.Errorf("invalid integer literal %q", s)
+	}
+	// ParseInt with base 0 reads the same prefixes and underscores
+	n, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("integer literal %q overflows int64", s)
+	}
+	return n, nil
+}
+
+// ParseIntegerBig is ParseIntegerValue without the int64 limit: it
+// validates s the same way and returns its value at any size.
+func ParseIntegerBig(s string) (*big.Int, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errEmptyInput
+	}
+	if !isValidInteger(s) {
+		return nil, fmt.Errorf("invalid integer literal %q", s)
+	}
+	// like ParseInt, SetString with base 0 reads prefixes and underscores
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer literal %q", s)
+	}
+	return n, nil
+}
+
+// DetectBase classifies an integer literal by its prefix alone: 2 for
+// "0b", 8 for "0o" or a legacy leading zero ("0755"), 16 for "0x", and 10
+// otherwise. The digits after the prefix are not checked, so "0b12" still
+// reports 2; ok is false only when s doesn't start with a digit.
+func DetectBase(s string) (base int, ok bool) {
+	if s == "" || !isDigit(rune(s[0])) {
+		return 0, false
+	}
+	if s[0] != '0' || len(s) == 1 {
+		return 10, true
+	}
+
+	switch c := rune(s[1]); {
+	case c == 'b' || c == 'B':
+		return 2, true
+	case c == 'o' || c == 'O':
+		return 8, true
+	case c == 'x' || c == 'X':
+		return 16, true
+	case isDigit(c) || c == '_':
+		return 8, true // legacy octal: 0755, 0_755
+	}
+	return 10, true
+}
+
 // Example usage:
 // isValidInteger("0")         // true
 // isValidInteger("123")       // true
 // isValidInteger("0xFF")      // true
 // isValidInteger("0xDEADBEEF") // true
+// isValidInteger("0b1010")    // true
+// isValidInteger("0o755")     // true
 // isValidInteger("00")        // false
+// isValidIntegerStrict("0777")   // false
+// isValidIntegerLenient("0777")  // true
+// isValidHexWithCase("0xff", true)   // true
+// isValidHexWithCase("0xFf", false)  // false
+// isValidInteger("1_000_000")  // true
+// isValidInteger("1__000")     // false
+// ParseIntegerValue("0xFF")    // 255, nil
+// ParseIntegerValue("1_000")   // 1000, nil
+// ParseIntegerBig("1" + strings.Repeat("0", 39))  // 10^39, nil
+// DetectBase("0b101")  // 2, true
+// DetectBase("0755")   // 8, true (legacy octal)
+// DetectBase("42")     // 10, true
+// DetectBase("x42")    // 0, false
 
 // ============================================================================
 // 8. COMPLETE EXAMPLE - For Statement
@@ -253,6 +753,9 @@ type ForStatement struct {
 
 func parseForStatement(stmt string) (ForStatement, error) {
 	stmt = strings.TrimSpace(stmt)
+	if stmt == "" {
+		return ForStatement{}, errEmptyInput
+	}
 
 	if !strings.HasPrefix(stmt, "for") {
 		return ForStatement{}, fmt.Errorf("not a for statement")
@@ -282,6 +785,14 @@ func parseForStatement(stmt string) (ForStatement, error) {
 	return fs, nil
 }
 
+// String rebuilds the statement: "for x < 10 { ... }".
+func (fs ForStatement) String() string {
+	if fs.Content == "" {
+		return "for"
+	}
+	return "for " + fs.Content
+}
+
 // Example usage:
 // parseForStatement("for x < 10 { ... }")           // condition
 // parseForStatement("for i := 0; i < 10; i++ { ... }") // clause
@@ -291,7 +802,7 @@ func parseForStatement(stmt string) (ForStatement, error) {
 // ============================================================================
 // 9. PRACTICAL EXAMPLE - Function Call
 // ============================================================================
-// EBNF: FunctionCall = identifier "(" [ ArgumentList ] ")" .
+// EBNF: FunctionCall = identifier { "." identifier } "(" [ ArgumentList ] ")" .
 //       ArgumentList = Argument { "," Argument } .
 //       Argument = Expression | identifier "=" Expression .
 
@@ -301,30 +812,71 @@ type FunctionCall struct {
 }
 
 func parseFunctionCall(call string) (FunctionCall, error) {
+	return ParseFunctionCallSep(call, ',')
+}
+
+// ParseFunctionCallSep is parseFunctionCall for call-like syntax that
+// separates arguments with something other than a comma, such as ';' or
+// ' '. Only sep splits, so commas become part of an argument. A whitespace
+// sep treats a run of it as one separator. String and FormatCall always
+// print ", ", so they don't round-trip other separators.
+func ParseFunctionCallSep(call string, sep rune) (FunctionCall, error) {
+	if strings.TrimSpace(call) == "" {
+		return FunctionCall{}, errEmptyInput
+	}
+	end := utf8.RuneCountInString(call)
+
 	// Find opening parenthesis
 	parenIdx := strings.Index(call, "(")
 	if parenIdx == -1 {
-		return FunctionCall{}, fmt.Errorf("no opening parenthesis")
+		return FunctionCall{}, newParseError(end, "no opening parenthesis")
 	}
 
+	// The name is an identifier or a qualified (dotted) identifier
 	name := strings.TrimSpace(call[:parenIdx])
-
-	// Find closing parenthesis
-	closeIdx := strings.LastIndex(call, ")")
-	if closeIdx == -1 {
-		return FunctionCall{}, fmt.Errorf("no closing parenthesis")
+	namePos := utf8.RuneCountInString(call[:len(call)-len(strings.TrimLeft(call, " \t\n"))])
+	if name == "" {
+		return FunctionCall{}, newParseError(namePos, "missing function name")
+	}
+	for _, part := range strings.Split(name, ".") {
+		if err := validateIdentifier(part); err != nil {
+			return FunctionCall{}, newParseError(namePos, "invalid function name %q: %w", name, err)
+		}
 	}
 
-	// Parse arguments (comma-separated)
-	argsStr := strings.TrimSpace(call[parenIdx+1 : closeIdx])
-	args := []string{}
+	// Find closing parenthesis: the one matching parenIdx, which must end
+	// the call. When it is missing, the last ")" bounds the arguments so
+	// the split below can point at the bracket or literal left open.
+	closeIdx := matchingBracket(call, parenIdx)
+	unclosed := closeIdx == -1
+	if unclosed {
+		if closeIdx = strings.LastIndex(call, ")"); closeIdx < parenIdx {
+			return FunctionCall{}, newParseError(end, "no closing parenthesis")
+		}
+	} else if rest := strings.TrimSpace(call[closeIdx+1:]); rest != "" {
+		return FunctionCall{}, newParseError(utf8.RuneCountInString(call[:closeIdx+1]), "unexpected %q after call", rest)
+	}
 
-	if argsStr != "" { // optional arguments
-		parts := strings.Split(argsStr, ",")
-		for _, part := range parts {
-			args = append(args, strings.TrimSpace(part))
+	// Parse arguments (sep-separated, optional, with an optional trailing
+	// sep); separators inside nested calls or literals do not split.
+	// Argument errors point at the argument at fault.
+	argsAt := parenIdx + 1
+	argPos := func(offset int) int { return utf8.RuneCountInString(call[:argsAt+offset]) }
+	args, starts, err := splitDelimitedAt(call[argsAt:closeIdx], sep, true)
+	if err != nil {
+		return FunctionCall{}, newParseError(argPos(starts[len(starts)-1]), "invalid arguments: %w", err)
+	}
+	if unicode.IsSpace(sep) {
+		args = slices.DeleteFunc(args, func(arg string) bool { return arg == "" })
+	}
+	for i, arg := range args {
+		if arg == "" {
+			return FunctionCall{}, newParseError(argPos(starts[i]), "missing argument %d in %q", i+1, call)
 		}
 	}
+	if unclosed {
+		return FunctionCall{}, newParseError(end, "no closing parenthesis")
+	}
 
 	return FunctionCall{
 		Name:      name,
@@ -332,19 +884,166 @@ func parseFunctionCall(call string) (FunctionCall, error) {
 	}, nil
 }
 
+// ParseFunctionCallLimited is parseFunctionCall for linting call sites: it
+// also fails when the call has more than maxArgs arguments. maxArgs <= 0
+// means no limit.
+func ParseFunctionCallLimited(call string, maxArgs int) (FunctionCall, error) {
+	fc, err := parseFunctionCall(call)
+	if err != nil {
+		return FunctionCall{}, err
+	}
+	if maxArgs > 0 && len(fc.Arguments) > maxArgs {
+		return FunctionCall{}, fmt.Errorf("%s has %d arguments, more than the limit of %d", fc.Name, len(fc.Arguments), maxArgs)
+	}
+	return fc, nil
+}
+
+// String rebuilds the call with ", " between arguments: "add(2, 3)".
+func (fc FunctionCall) String() string {
+	return FormatCall(fc)
+}
+
+// FormatCall prints fc with canonical spacing: no space around the name or
+// inside the parentheses and one space after each comma, so hand-built
+// calls with stray whitespace print the same as parsed ones.
+func FormatCall(fc FunctionCall) string {
+	args := make([]string, len(fc.Arguments))
+	for i, arg := range fc.Arguments {
+		args[i] = strings.TrimSpace(arg)
+	}
+	return strings.TrimSpace(fc.Name) + "(" + strings.Join(args, ", ") + ")"
+}
+
 // Example usage:
 // parseFunctionCall("fmt.Println()")              // {Name: "fmt.Println", Args: []}
 // parseFunctionCall("fmt.Println(\"Hello\")")     // {Name: "fmt.Println", Args: ["Hello"]}
 // parseFunctionCall("add(2, 3)")                  // {Name: "add", Args: ["2", "3"]}
+// parseFunctionCall("add(2, 3,)")                 // {Name: "add", Args: ["2", "3"]}
+// parseFunctionCall("add(2,,3)")                  // error: missing argument 2
+// parseFunctionCall("f(1)xyz")                    // error: unexpected "xyz" after call
+// parseFunctionCall("1 2(3)")                     // error: invalid function name
+// FormatCall(FunctionCall{Name: "add", Arguments: []string{" 1", "2 "}})  // "add(1, 2)"
+// ParseFunctionCallLimited("f(a, b, c)", 2)   // error: f has 3 arguments, more than the limit of 2
+// ParseFunctionCallLimited("f(a, b, c)", 0)   // {Name: "f", Args: ["a", "b", "c"]}
+// ParseFunctionCallSep("max(1; 2, 3)", ';')  // {Name: "max", Args: ["1", "2, 3"]}
+// ParseFunctionCallSep("sum(a b  c)", ' ')   // {Name: "sum", Args: ["a", "b", "c"]}
+
+// parseKeyValue splits the named form of Argument, identifier "=" Expression.
+// It splits on the first "=" that is not part of "==", so "a==b" is not an
+// assignment. ok is false when there is no assignment, the key is not a
+// valid identifier, or the value is empty.
+func parseKeyValue(s string) (key, value string, ok bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '=' {
+			continue
+		}
+		if op, width := classifyAssignOp(s, i); op != "=" {
+			i += width - 1 // part of "==" or another operator
+			continue
+		}
+
+		key = strings.TrimSpace(s[:i])
+		value = strings.TrimSpace(s[i+1:])
+		if !isValidIdentifier(key) || value == "" {
+			return "", "", false
+		}
+		return key, value, true
+	}
+	return "", "", false
+}
+
+// Example usage:
+// parseKeyValue("x=1")        // "x", "1", true
+// parseKeyValue("flag=true")  // "flag", "true", true
+// parseKeyValue("a==b")       // "", "", false (comparison, not assignment)
+// parseKeyValue("=5")         // "", "", false (invalid key)
+
+// ============================================================================
+// SUPPORTED CONSTRUCTS
+// ============================================================================
+// The spec productions this package can parse or validate, by their names
+// in https://go.dev/ref/spec. Add to this list alongside each new parser.
+
+var supportedConstructs = []string{
+	"Boolean",         // isBoolean, ParseBool
+	"SignedNumber",    // parseSignedNumber
+	"FileExtension",   // parseFilename
+	"Digits",          // isDigits
+	"Identifier",      // isValidIdentifier, validateIdentifier
+	"IntLit",          // isValidInteger
+	"ForStmt",         // parseForStatement
+	"FunctionCall",    // parseFunctionCall
+	"Argument",        // parseKeyValue
+	"FieldDecl",       // parseStructField
+	"PackageClause",   // parsePackageClause
+	"Type",            // parseTypeSpec
+	"ArrayType",       // parseArrayType
+	"GoStmt",          // parseGoStatement
+	"DeferStmt",       // parseDeferStatement
+	"ReturnStmt",      // parseReturnStatement
+	"Label",           // isValidLabel
+	"ShortVarDecl",    // parseShortVarDecl
+	"Index",           // parseIndexExpr
+	"Slice",           // parseSliceExpr
+	"BinaryExpr",      // splitBinaryExpr
+	"CompositeLit",    // parseCompositeLiteral
+	"SendStmt",        // parseChannelOp
+	"Production",      // ParseGrammar
+	"Token",           // Tokenize
+	"MapType",         // parseMapType
+	"IfStmt",          // parseIfStatement
+	"SwitchStmt",      // parseSwitchStatement
+	"Tag",             // parseStructTag
+	"Assignment",      // parseAssignment
+	"ImportDecl",      // parseImportSpec, parseImportBlock
+	"ParameterList",   // parseParamList
+	"Signature",       // parseFuncSignature
+	"Expression",      // parseExpression
+	"TypeParameters",  // parseTypeParams
+	"BuildConstraint", // parseBuildConstraint
+	"VarDecl",         // parseGenDecl, parseVarSpec
+	"ConstDecl",       // parseGenDecl, parseConstSpec
+	"TypeDecl",        // parseGenDecl, parseTypeDef
+	"TypeAssertion",   // parseTypeAssertion
+	"CommClause",      // parseSelectCase
+	"RequireSpec",     // parseModRequire (go.mod)
+	"SemVer",          // isValidSemVer
+	"GotoStmt",        // parseGotoStatement
+	"LabeledStmt",     // parseLabeledStatement
+	"RangeClause",     // parseRangeClause
+}
+
+// ListSupportedConstructs returns the names of the grammar constructs the
+// package currently handles, in the order they were added.
+func ListSupportedConstructs() []string {
+	return append([]string(nil), supportedConstructs...)
+}
 
 // ============================================================================
 // MAIN - Demonstrate all examples
 // ============================================================================
 
+// repeatString returns s repeated n times; n <= 0 yields "". Go strings
+// cannot be multiplied like Python's "=" * 70.
+func repeatString(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(s, n)
+}
+
 func main() {
-	fmt.Println("=" * 70)
+	if len(os.Args) > 1 && os.Args[1] == "repl" {
+		if err := RunREPL(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println(repeatString("=", 70))
 	fmt.Println("EBNF NOTATION EXAMPLES IN GO")
-	fmt.Println("=" * 70)
+	fmt.Println(repeatString("=", 70))
 
 	// 1. Alternation
 	fmt.Println("\n1. ALTERNATION (|) - Choose ONE option")
@@ -413,5 +1112,5 @@ func main() {
 	fc2, _ := parseFunctionCall("add(2, 3)")
 	fmt.Printf("   parseFunctionCall(\"add(2, 3)\"): %+v\n", fc2)
 
-	fmt.Println("\n" + "="*70)
+	fmt.Println("\n" + repeatString("=", 70))
 }
@@ -0,0 +1,1312 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestValidateIdentifiers(t *testing.T) {
+	t.Run("reports only the invalid names", func(t *testing.T) {
+		names := []string{"name", "123var", "_private", "my-var", "", "MY_CONST"}
+		got := ValidateIdentifiers(names)
+
+		wantInvalid := []string{"123var", "my-var", ""}
+		if len(got) != len(wantInvalid) {
+			t.Fatalf("got %d invalid names %v want %d", len(got), got, len(wantInvalid))
+		}
+		for _, name := range wantInvalid {
+			if got[name] == nil {
+				t.Errorf("expected an error for %q", name)
+			}
+		}
+		for _, name := range []string{"name", "_private", "MY_CONST"} {
+			if err, ok := got[name]; ok {
+				t.Errorf("valid name %q reported as invalid: %v", name, err)
+			}
+		}
+	})
+	t.Run("empty slice has no failures", func(t *testing.T) {
+		got := ValidateIdentifiers([]string{})
+		if len(got) != 0 {
+			t.Errorf("got %v want no failures", got)
+		}
+	})
+}
+
+func TestValidateIdentifierUnicode(t *testing.T) {
+	for _, name := range []string{"café", "日本語", "_ñ", "x٣"} {
+		if err := validateIdentifier(name); err != nil {
+			t.Errorf("validateIdentifier(%q) unexpected error: %v", name, err)
+		}
+	}
+
+	// positions are rune indexes, which differ from byte offsets here
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"my-var", `invalid character '-' at position 2 in identifier "my-var"`},
+		{"café-x", `invalid character '-' at position 4 in identifier "café-x"`},
+		{"日本 語", `invalid character ' ' at position 2 in identifier "日本 語"`},
+		{"ñ€", `invalid character '€' at position 1 in identifier "ñ€"`},
+		{"１２", `identifier "１２" must start with a letter or underscore`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			err := validateIdentifier(tt.input)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLongestMatch(t *testing.T) {
+	tests := []struct {
+		input      string
+		candidates []string
+		want       string
+	}{
+		{"== b", []string{"=", "=="}, "=="},
+		{"== b", []string{"==", "="}, "=="},
+		{"= b", []string{"=", "=="}, "="},
+		{"<<= 2", []string{"<", "<<=", "<<"}, "<<="},
+		{"<< 2", []string{"<", "<<=", "<<"}, "<<"},
+		{"&^x", []string{"&", "&&", "&^"}, "&^"},
+		{"int64", []string{"int", "int64", "in"}, "int64"},
+		{"ab", []string{"a", "", "b"}, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.input, tt.candidates), func(t *testing.T) {
+			got, ok := longestMatch(tt.input, tt.candidates)
+			if !ok || got != tt.want {
+				t.Errorf("longestMatch(%q, %q) = %q, %v want %q, true", tt.input, tt.candidates, got, ok, tt.want)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		input      string
+		candidates []string
+	}{
+		{"x == y", []string{"=", "=="}},
+		{"=", []string{"=="}},
+		{"", []string{"=", ""}},
+		{"abc", nil},
+	} {
+		t.Run(fmt.Sprint("no match ", tt.input, tt.candidates), func(t *testing.T) {
+			if got, ok := longestMatch(tt.input, tt.candidates); ok {
+				t.Errorf("longestMatch(%q, %q) = %q, true want no match", tt.input, tt.candidates, got)
+			}
+		})
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	accepted := []struct {
+		input string
+		want  bool
+	}{
+		{"true", true},
+		{"True", true},
+		{"TRUE", true},
+		{"false", false},
+		{"False", false},
+		{"FALSE", false},
+	}
+	for _, tt := range accepted {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseBool(tt.input)
+			if !ok || got != tt.want {
+				t.Errorf("ParseBool(%q) = %v, %v want %v, true", tt.input, got, ok, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"yes", "1", "t", "tRuE", "", " true"} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			if _, ok := ParseBool(input); ok {
+				t.Errorf("ParseBool(%q) accepted, want rejected", input)
+			}
+		})
+	}
+
+	t.Run("isBoolean stays strict", func(t *testing.T) {
+		if isBoolean("True") {
+			t.Errorf("isBoolean(%q) = true want false", "True")
+		}
+	})
+}
+
+func TestParseBoolStrict(t *testing.T) {
+	for input, want := range map[string]bool{"true": true, "false": false} {
+		t.Run(input, func(t *testing.T) {
+			got, err := ParseBoolStrict(input)
+			if err != nil || got != want {
+				t.Errorf("ParseBoolStrict(%q) = %v, %v want %v, nil", input, got, err, want)
+			}
+		})
+	}
+
+	for _, input := range []string{"maybe", "True", "FALSE", "1", " true"} {
+		t.Run("error "+input, func(t *testing.T) {
+			_, err := ParseBoolStrict(input)
+			want := fmt.Sprintf("invalid boolean %q", input)
+			if err == nil || err.Error() != want {
+				t.Errorf("ParseBoolStrict(%q) error = %v want %s", input, err, want)
+			}
+		})
+	}
+}
+
+func TestParseKeyValue(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantKey   string
+		wantValue string
+		wantOK    bool
+	}{
+		{"x=1", "x", "1", true},
+		{"flag=true", "flag", "true", true},
+		{"name = \"go\"", "name", "\"go\"", true},
+		{"a==b", "", "", false},
+		{"=5", "", "", false},
+		{"noassign", "", "", false},
+		{"x=", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			key, value, ok := parseKeyValue(tt.input)
+			if key != tt.wantKey || value != tt.wantValue || ok != tt.wantOK {
+				t.Errorf("parseKeyValue(%q) = %q, %q, %v want %q, %q, %v",
+					tt.input, key, value, ok, tt.wantKey, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func FuzzParseFunctionCall(f *testing.F) {
+	seeds := []string{
+		"fmt.Println()",
+		"fmt.Println(\"Hello\")",
+		"add(2, 3)",
+		"outer(inner(1, 2), 3)",
+		"f(g(h(x)))",
+		"say(\"a, b\", 'c')",
+		"f)(",
+		"",
+		"(",
+		")",
+		"f(1)xyz",
+		"(x)",
+		"f(1)(2)",
+		" pkg.F ( a ,b, ) ",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, call string) {
+		fc, err := parseFunctionCall(call)
+		if err != nil {
+			return
+		}
+
+		serialized := fc.Name + "(" + strings.Join(fc.Arguments, ", ") + ")"
+		if want := canonicalCall(call); serialized != want {
+			t.Errorf("parseFunctionCall(%q) serialized to %q, want %q", call, serialized, want)
+		}
+		again, err := parseFunctionCall(serialized)
+		if err != nil {
+			t.Fatalf("re-parsing %q (from %q) failed: %v", serialized, call, err)
+		}
+		if !reflect.DeepEqual(again, fc) {
+			t.Errorf("round trip of %q via %q: got %+v want %+v", call, serialized, again, fc)
+		}
+	})
+}
+
+// canonicalCall is the fuzz oracle for parseFunctionCall: call with the
+// outer space trimmed, no space around the top-level parentheses and
+// commas, one space after each comma, and no trailing comma. Brackets and
+// literals inside the arguments are copied as they are.
+func canonicalCall(call string) string {
+	s := strings.TrimSpace(call)
+	out := ""
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\'', '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				end = len(s) - 1
+			}
+			out += s[i : end+1]
+			i = end
+			continue
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+
+		outer := (c == '(' && depth == 1) || (c == ',' && depth == 1) || (c == ')' && depth == 0)
+		if !outer {
+			out += s[i : i+1]
+			continue
+		}
+		out = strings.TrimRightFunc(out, unicode.IsSpace)
+		switch c {
+		case ',':
+			out += ", "
+		case ')':
+			out = strings.TrimSuffix(out, ",") + ")"
+		default:
+			out += "("
+		}
+		rest := strings.TrimLeftFunc(s[i+1:], unicode.IsSpace)
+		i = len(s) - len(rest) - 1
+	}
+	return out
+}
+
+// FuzzIsValidInteger cross-checks isValidIntegerLenient against strconv.ParseUint
+// with base 0, which implements Go's integer literal syntax. Intentional
+// divergences:
+//   - strconv reports out-of-range values as ErrRange, possibly before it
+//     has seen every digit, so those are re-checked with big.Int, which has
+//     no range limit.
+//   - Signs are not part of IntLit; ParseUint rejects them too, so no
+//     ParseInt cross-check is needed.
+//   - strconv accepts legacy octal ("0777"), so the lenient mode is the one
+//     compared; isValidInteger itself is strict.
+func FuzzIsValidInteger(f *testing.F) {
+	seeds := []string{
+		"0", "7", "123", "18446744073709551616",
+		"0x0", "0xFF", "0XDEADBEEF", "0xdeadbeef",
+		"0b1010", "0B1", "0o755", "0O17", "0b2", "0o8",
+		"0777", "00", "08",
+		"", "00", "0x", "0xG", "12a", "-1", "+1", " 1", "1 ",
+		"1_000", "0x_FF", "0_7", "0b_1", "1__0", "_1", "1_", "0x_", "0_x1",
+		"0_b1", "0_B0",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		_, err := strconv.ParseUint(s, 0, 64)
+		want := err == nil
+		if errors.Is(err, strconv.ErrRange) {
+			_, want = new(big.Int).SetString(s, 0)
+		}
+		if got := isValidIntegerLenient(s); got != want {
+			t.Errorf("isValidIntegerLenient(%q) = %v, strconv says %v (err: %v)", s, got, want, err)
+		}
+	})
+}
+
+func TestRepeatString(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"=", 0, ""},
+		{"=", 3, "==="},
+		{"ab", 3, "ababab"},
+		{"=", -1, ""},
+	}
+
+	for _, tt := range tests {
+		got := repeatString(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("repeatString(%q, %d) = %q want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		input string
+		want  File
+	}{
+		{"document.txt", File{Name: "document", Extension: "txt"}},
+		{"README", File{Name: "README"}},
+		{"src/main.go", File{Dir: "src", Name: "main", Extension: "go"}},
+		{"a/b/c/util.go", File{Dir: "a/b/c", Name: "util", Extension: "go"}},
+		{"/etc/hosts", File{Dir: "/etc", Name: "hosts"}},
+		{"/init.rc", File{Dir: "/", Name: "init", Extension: "rc"}},
+		{"src/", File{Dir: "src"}},
+		{"archive.tar.gz", File{Name: "archive.tar", Extension: "gz"}},
+		{"v1.2/notes.md", File{Dir: "v1.2", Name: "notes", Extension: "md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := parseFilename(tt.input)
+			if got != tt.want {
+				t.Errorf("parseFilename(%q) = %+v want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilenameWithOptions(t *testing.T) {
+	tests := []struct {
+		input    string
+		lowerExt bool
+		want     File
+	}{
+		{"IMAGE.JPG", true, File{Name: "IMAGE", Extension: "jpg"}},
+		{"IMAGE.JPG", false, File{Name: "IMAGE", Extension: "JPG"}},
+		{"Photos/IMG_01.Jpeg", true, File{Dir: "Photos", Name: "IMG_01", Extension: "jpeg"}},
+		{"README", true, File{Name: "README"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%t", tt.input, tt.lowerExt), func(t *testing.T) {
+			got := parseFilenameWithOptions(tt.input, tt.lowerExt)
+			if got != tt.want {
+				t.Errorf("parseFilenameWithOptions(%q, %t) = %+v want %+v", tt.input, tt.lowerExt, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("parseFilename keeps the case", func(t *testing.T) {
+		if got := parseFilename("IMAGE.JPG").Extension; got != "JPG" {
+			t.Errorf("got %q want %q", got, "JPG")
+		}
+	})
+}
+
+func TestParseFilenameFull(t *testing.T) {
+	tests := []struct {
+		input          string
+		wantName       string
+		wantExtensions []string
+	}{
+		{"archive.tar.gz", "archive", []string{"tar", "gz"}},
+		{"document.txt", "document", []string{"txt"}},
+		{"README", "README", []string{}},
+		{"src/lib.min.js", "lib", []string{"min", "js"}},
+		{"v1.2/notes", "notes", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			name, extensions := parseFilenameFull(tt.input)
+			if name != tt.wantName || !reflect.DeepEqual(extensions, tt.wantExtensions) {
+				t.Errorf("got %q, %q want %q, %q", name, extensions, tt.wantName, tt.wantExtensions)
+			}
+		})
+	}
+
+	t.Run("parseFilename keeps only the last", func(t *testing.T) {
+		if got := parseFilename("archive.tar.gz").Extension; got != "gz" {
+			t.Errorf("got %q want %q", got, "gz")
+		}
+	})
+}
+
+func TestIsHexDigit(t *testing.T) {
+	for _, c := range []rune{'0', '9', 'a', 'f', 'A', 'F'} {
+		if !isHexDigit(c) {
+			t.Errorf("isHexDigit(%q) = false want true", c)
+		}
+	}
+	for _, c := range []rune{'g', 'G', ' '} {
+		if isHexDigit(c) {
+			t.Errorf("isHexDigit(%q) = true want false", c)
+		}
+	}
+}
+
+func TestIsOctalDigit(t *testing.T) {
+	if !isOctalDigit('7') {
+		t.Errorf("isOctalDigit('7') = false want true")
+	}
+	if isOctalDigit('8') {
+		t.Errorf("isOctalDigit('8') = true want false")
+	}
+}
+
+func TestIsBinaryDigit(t *testing.T) {
+	if !isBinaryDigit('1') {
+		t.Errorf("isBinaryDigit('1') = false want true")
+	}
+	if isBinaryDigit('2') {
+		t.Errorf("isBinaryDigit('2') = true want false")
+	}
+}
+
+func TestIsValidInteger(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"0", true},
+		{"123", true},
+		{"0xFF", true},
+		{"0b1010", true},
+		{"0B1", true},
+		{"0o755", true},
+		{"0O17", true},
+		{"0b", false},
+		{"0b102", false},
+		{"0o", false},
+		{"0o78", false},
+		{"00", false},
+		{"1_000", true},
+		{"0x_FF", true},
+		{"0b1_0", true},
+		{"1__0", false},
+		{"1_", false},
+		{"0_x1", false},
+		{"0_b1", false},
+		{"0_B0", false},
+		{"0_o7", false},
+		{"0_7", false}, // legacy octal, accepted only in lenient mode
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := isValidInteger(tt.input); got != tt.want {
+				t.Errorf("isValidInteger(%q) = %v want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidHexWithCase(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantLower bool
+		wantUpper bool
+	}{
+		{"0xff", true, false},
+		{"0xFF", false, true},
+		{"0xFf", false, false},
+		{"0xdeadBEEF", false, false},
+		{"0x1234", true, true}, // no letters, so either case is fine
+		{"0XAB", false, true},  // the prefix is not a digit
+		{"0xfg", false, false},
+		{"0x", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := isValidHexWithCase(tt.input, true); got != tt.wantLower {
+				t.Errorf("isValidHexWithCase(%q, true) = %v want %v", tt.input, got, tt.wantLower)
+			}
+			if got := isValidHexWithCase(tt.input, false); got != tt.wantUpper {
+				t.Errorf("isValidHexWithCase(%q, false) = %v want %v", tt.input, got, tt.wantUpper)
+			}
+		})
+	}
+
+	t.Run("isValidHex ignores case", func(t *testing.T) {
+		for _, input := range []string{"0xff", "0xFF", "0xFf", "0XdeadBEEF"} {
+			if !isValidHex(input) {
+				t.Errorf("isValidHex(%q) = false want true", input)
+			}
+		}
+	})
+}
+
+func TestParseIntegerValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"0", 0},
+		{"42", 42},
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0b1010", 10},
+		{"0o17", 15},
+		{"1_000", 1000},
+		{"0x_dead_beef", 0xdeadbeef},
+		{"9223372036854775807", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseIntegerValue(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d want %d", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"9223372036854775808", // overflow
+		"0xFFFFFFFFFFFFFFFFF", // overflow
+		"", "abc", "12a", "-1", "0x", "1__0", "1_", "_1", "0755",
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := ParseIntegerValue(input); err == nil {
+				t.Errorf("ParseIntegerValue(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseIntegerBig(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // decimal
+	}{
+		{"0", "0"},
+		{"0xFF", "255"},
+		{"1_000", "1000"},
+		{"9223372036854775808", "9223372036854775808"},
+		{"1234567890123456789012345678901234567890", "1234567890123456789012345678901234567890"},
+		{"0xFFFF_FFFF_FFFF_FFFF_FFFF_FFFF_FFFF_FFFF", "340282366920938463463374607431768211455"},
+		{"0b1" + strings.Repeat("0", 70), "1180591620717411303424"},
+		{"0o7777777777777777777777777", "37778931862957161709567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseIntegerBig(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"", "abc", "12a", "-1", "0x", "1__0", "1_", "_1", "0755", "0xG",
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := ParseIntegerBig(input); err == nil {
+				t.Errorf("ParseIntegerBig(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestDetectBase(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantBase int
+		wantOK   bool
+	}{
+		{"0b101", 2, true},
+		{"0B1", 2, true},
+		{"0o17", 8, true},
+		{"0O17", 8, true},
+		{"0755", 8, true}, // legacy octal
+		{"0xFF", 16, true},
+		{"0X1f", 16, true},
+		{"42", 10, true},
+		{"0", 10, true},
+		{"0b12", 2, true}, // digits are not validated
+		{"0.5", 10, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"-1", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			base, ok := DetectBase(tt.input)
+			if base != tt.wantBase || ok != tt.wantOK {
+				t.Errorf("DetectBase(%q) = %d, %v want %d, %v", tt.input, base, ok, tt.wantBase, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseSignedNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  SignedNumber
+	}{
+		{"+42", SignedNumber{"+", 42}},
+		{"-15", SignedNumber{"-", 15}},
+		{"99", SignedNumber{"+", 99}},
+		{"  7 ", SignedNumber{"+", 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSignedNumber(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignedNumberValue(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"+42", 42},
+		{"-15", -15},
+		{"99", 99},
+		{"-0", 0},
+	}
+
+	for _, tt := range tests {
+		sn, err := parseSignedNumber(tt.input)
+		if err != nil {
+			t.Fatalf("parseSignedNumber(%q): %v", tt.input, err)
+		}
+		if got := sn.Value(); got != tt.want {
+			t.Errorf("parseSignedNumber(%q).Value() = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+
+	if got := (SignedNumber{Number: 7}).Value(); got != 7 {
+		t.Errorf("empty Sign: Value() = %d, want 7", got)
+	}
+}
+
+func TestSignedNumberArithmetic(t *testing.T) {
+	pos := func(n int) SignedNumber { return SignedNumber{"+", n} }
+	neg := func(n int) SignedNumber { return SignedNumber{"-", n} }
+
+	addTests := []struct {
+		a, b, want SignedNumber
+	}{
+		{pos(2), pos(3), pos(5)},
+		{neg(15), pos(20), pos(5)},
+		{pos(5), neg(8), neg(3)},
+		{neg(4), neg(4), neg(8)},
+		{pos(6), neg(6), pos(0)},
+	}
+	for _, tt := range addTests {
+		if got := tt.a.Add(tt.b); got != tt.want {
+			t.Errorf("%v.Add(%v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	negateTests := []struct {
+		in, want SignedNumber
+	}{
+		{pos(15), neg(15)},
+		{neg(15), pos(15)},
+		{pos(0), pos(0)},
+		{neg(0), pos(0)},
+		{SignedNumber{Number: 3}, neg(3)},
+	}
+	for _, tt := range negateTests {
+		if got := tt.in.Negate(); got != tt.want {
+			t.Errorf("%v.Negate() = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSignedNumberNormalize(t *testing.T) {
+	tests := []struct {
+		in, want SignedNumber
+	}{
+		{SignedNumber{"-", 0}, SignedNumber{"+", 0}},
+		{SignedNumber{"+", 0}, SignedNumber{"+", 0}},
+		{SignedNumber{"", 0}, SignedNumber{"+", 0}},
+		{SignedNumber{"-", 5}, SignedNumber{"-", 5}},
+		{SignedNumber{"+", 5}, SignedNumber{"+", 5}},
+		{SignedNumber{"", 5}, SignedNumber{"+", 5}},
+		{SignedNumber{"+", -5}, SignedNumber{"-", 5}},
+	}
+
+	for _, tt := range tests {
+		got := tt.in.Normalize()
+		if got != tt.want {
+			t.Errorf("%#v.Normalize() = %#v, want %#v", tt.in, got, tt.want)
+		}
+		if again := got.Normalize(); again != got {
+			t.Errorf("Normalize is not idempotent: %#v then %#v", got, again)
+		}
+	}
+
+	for _, pair := range [][2]string{{"-0", "+0"}, {"0", "+0"}, {"7", "+7"}} {
+		a, _ := parseSignedNumber(pair[0])
+		b, _ := parseSignedNumber(pair[1])
+		if a.Normalize() != b.Normalize() {
+			t.Errorf("%q and %q normalize to %v and %v", pair[0], pair[1], a.Normalize(), b.Normalize())
+		}
+	}
+}
+
+func TestParseSignedNumberLoneSign(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"+", `no digits after sign "+" at position 1`},
+		{"-", `no digits after sign "-" at position 1`},
+		{"+x", `expected digit after sign "+", found 'x' at position 1`},
+		{"- 5", `expected digit after sign "-", found ' ' at position 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseSignedNumber(tt.input)
+			if err == nil {
+				t.Fatalf("parseSignedNumber(%q) expected an error", tt.input)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("got error %q want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsValidDecimal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"0", true},
+		{"7", true},
+		{"1234567890", true},
+		{"", false},
+		{"00", false},
+		{"012", false},
+		{"12a", false},
+		{"１２", false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidDecimal(tt.input); got != tt.want {
+			t.Errorf("isValidDecimal(%q) = %v want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidDecimalPadded(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantPadded bool
+		wantStrict bool
+	}{
+		{"0", true, true},
+		{"42", true, true},
+		{"007", true, false},
+		{"000", true, false},
+		{"0100", true, false},
+		{"", false, false},
+		{"0x7", false, false},
+		{"-7", false, false},
+		{"0_7", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidDecimalPadded(tt.input); got != tt.wantPadded {
+			t.Errorf("isValidDecimalPadded(%q) = %v want %v", tt.input, got, tt.wantPadded)
+		}
+		if got := isValidDecimal(tt.input); got != tt.wantStrict {
+			t.Errorf("isValidDecimal(%q) = %v want %v", tt.input, got, tt.wantStrict)
+		}
+	}
+}
+
+func TestIsValidIdentifierLoose(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantStrict bool
+		wantLoose  bool
+	}{
+		{"name", true, true},
+		{" name", false, true},
+		{"\tname \n", false, true},
+		{"na me", false, false},
+		{"   ", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := isValidIdentifier(tt.input); got != tt.wantStrict {
+				t.Errorf("isValidIdentifier(%q) = %v want %v", tt.input, got, tt.wantStrict)
+			}
+			if got := IsValidIdentifierLoose(tt.input); got != tt.wantLoose {
+				t.Errorf("IsValidIdentifierLoose(%q) = %v want %v", tt.input, got, tt.wantLoose)
+			}
+		})
+	}
+
+	if got := NormalizeIdentifier("  name\t"); got != "name" {
+		t.Errorf("NormalizeIdentifier got %q want %q", got, "name")
+	}
+}
+
+func TestMatchAlternation(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		options   []string
+		wantIndex int
+		wantOK    bool
+	}{
+		{"matching option", "false", []string{"true", "false"}, 1, true},
+		{"first match wins", "a", []string{"a", "b", "a"}, 0, true},
+		{"no match", "maybe", []string{"true", "false"}, -1, false},
+		{"empty options", "true", nil, -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, ok := MatchAlternation(tt.input, tt.options)
+			if index != tt.wantIndex || ok != tt.wantOK {
+				t.Errorf("MatchAlternation(%q, %q) = %d, %v want %d, %v",
+					tt.input, tt.options, index, ok, tt.wantIndex, tt.wantOK)
+			}
+		})
+	}
+
+	for input, want := range map[string]bool{"true": true, "false": true, "maybe": false, "True": false} {
+		if got := isBoolean(input); got != want {
+			t.Errorf("isBoolean(%q) = %v want %v", input, got, want)
+		}
+	}
+}
+
+func TestStringers(t *testing.T) {
+	tests := []struct {
+		value fmt.Stringer
+		want  string
+	}{
+		{SignedNumber{Sign: "+", Number: 42}, "+42"},
+		{SignedNumber{Sign: "-", Number: 15}, "-15"},
+		{SignedNumber{Number: 7}, "+7"},
+		{File{Name: "document", Extension: "txt"}, "document.txt"},
+		{File{Name: "README"}, "README"},
+		{File{Dir: "src", Name: "main", Extension: "go"}, "src/main.go"},
+		{File{Dir: "/", Name: "init", Extension: "rc"}, "/init.rc"},
+		{ForStatement{ConditionType: "condition", Content: "x < 10 { }"}, "for x < 10 { }"},
+		{ForStatement{ConditionType: "infinite"}, "for"},
+		{FunctionCall{Name: "add", Arguments: []string{"2", "3"}}, "add(2, 3)"},
+		{FunctionCall{Name: "fmt.Println", Arguments: []string{}}, "fmt.Println()"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	t.Run("FunctionCall", func(t *testing.T) {
+		for _, input := range []string{"add(2, 3)", "fmt.Println()", "f(\"hi\")"} {
+			fc, err := parseFunctionCall(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			again, err := parseFunctionCall(fc.String())
+			if err != nil {
+				t.Fatalf("re-parsing %q: %v", fc.String(), err)
+			}
+			if !reflect.DeepEqual(again, fc) {
+				t.Errorf("round trip of %q got %+v want %+v", input, again, fc)
+			}
+		}
+	})
+	t.Run("File", func(t *testing.T) {
+		for _, input := range []string{"document.txt", "README", "src/main.go", "/etc/hosts", "/init.rc"} {
+			f := parseFilename(input)
+			if got := parseFilename(f.String()); got != f {
+				t.Errorf("round trip of %q got %+v want %+v", input, got, f)
+			}
+		}
+	})
+	t.Run("SignedNumber", func(t *testing.T) {
+		for _, input := range []string{"+42", "-15", "99"} {
+			sn, _ := parseSignedNumber(input)
+			if got, _ := parseSignedNumber(sn.String()); got != sn {
+				t.Errorf("round trip of %q got %+v want %+v", input, got, sn)
+			}
+		}
+	})
+}
+
+func TestParseFunctionCallTrailingComma(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"add(1, 2)", []string{"1", "2"}},
+		{"add(1, 2,)", []string{"1", "2"}},
+		{"add(\n\t1,\n\t2,\n)", []string{"1", "2"}},
+		{"f()", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			fc, err := parseFunctionCall(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fc.Arguments, tt.want) {
+				t.Errorf("got %q want %q", fc.Arguments, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"f(1,,2)", "f(,)", "f(1,,)", "f(, 1)"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseFunctionCall(input); err == nil {
+				t.Errorf("parseFunctionCall(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseFunctionCallNesting(t *testing.T) {
+	nested := func(depth int) string {
+		return "f(" + strings.Repeat("(", depth) + "x" + strings.Repeat(")", depth) + ")"
+	}
+
+	t.Run("just under the limit", func(t *testing.T) {
+		fc, err := parseFunctionCall(nested(maxNestingDepth))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fc.Arguments) != 1 {
+			t.Errorf("got %d arguments want 1", len(fc.Arguments))
+		}
+	})
+	t.Run("over the limit", func(t *testing.T) {
+		_, err := parseFunctionCall(nested(maxNestingDepth + 1))
+		if err == nil || !strings.Contains(err.Error(), "nesting too deep") {
+			t.Errorf("got error %v want nesting too deep", err)
+		}
+	})
+	t.Run("a second call is trailing text", func(t *testing.T) {
+		_, err := parseFunctionCall("f(1)(2)")
+		if err == nil || !strings.Contains(err.Error(), `unexpected "(2)" after call`) {
+			t.Errorf("got error %v want the text after the call", err)
+		}
+	})
+	t.Run("nested arguments are not split", func(t *testing.T) {
+		fc, err := parseFunctionCall("outer(inner(1, 2), \"a, b\")")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"inner(1, 2)", "\"a, b\""}
+		if !reflect.DeepEqual(fc.Arguments, want) {
+			t.Errorf("got %q want %q", fc.Arguments, want)
+		}
+	})
+}
+
+func TestIsValidIntegerModes(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantStrict  bool
+		wantLenient bool
+	}{
+		{"0", true, true},
+		{"42", true, true},
+		{"0o777", true, true},
+		{"0777", false, true},
+		{"00", false, true},
+		{"007", false, true},
+		{"09", false, false},
+		{"0x1F", true, true},
+		{"0_7", false, true},
+		{"0_b1", false, false},
+		{"0_B0", false, false},
+		{"0_x1", false, false},
+		{"0x_a_b", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := isValidIntegerStrict(tt.input); got != tt.wantStrict {
+				t.Errorf("isValidIntegerStrict(%q) = %v want %v", tt.input, got, tt.wantStrict)
+			}
+			if got := isValidIntegerLenient(tt.input); got != tt.wantLenient {
+				t.Errorf("isValidIntegerLenient(%q) = %v want %v", tt.input, got, tt.wantLenient)
+			}
+			if got := isValidInteger(tt.input); got != tt.wantStrict {
+				t.Errorf("isValidInteger(%q) = %v, want it to match strict mode", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestListSupportedConstructs(t *testing.T) {
+	got := ListSupportedConstructs()
+
+	seen := map[string]bool{}
+	for _, name := range got {
+		if seen[name] {
+			t.Errorf("%q is listed twice", name)
+		}
+		seen[name] = true
+	}
+
+	for _, want := range []string{
+		"Boolean", "SignedNumber", "Identifier", "IntLit", "ForStmt", "FunctionCall",
+		"FieldDecl", "Type", "ArrayType", "PackageClause", "GoStmt", "DeferStmt",
+		"ReturnStmt", "ShortVarDecl", "Index", "Slice", "CompositeLit", "Production",
+	} {
+		if !seen[want] {
+			t.Errorf("ListSupportedConstructs() is missing %q", want)
+		}
+	}
+
+	got[0] = "changed"
+	if ListSupportedConstructs()[0] == "changed" {
+		t.Errorf("ListSupportedConstructs() must return a copy")
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	parsers := map[string]func(string) error{
+		"parseSignedNumber":     func(s string) error { _, err := parseSignedNumber(s); return err },
+		"ParseIntegerValue":     func(s string) error { _, err := ParseIntegerValue(s); return err },
+		"ParseIntegerBig":       func(s string) error { _, err := ParseIntegerBig(s); return err },
+		"ParseBoolStrict":       func(s string) error { _, err := ParseBoolStrict(s); return err },
+		"parseForStatement":     func(s string) error { _, err := parseForStatement(s); return err },
+		"parseFunctionCall":     func(s string) error { _, err := parseFunctionCall(s); return err },
+		"parseStructField":      func(s string) error { _, err := parseStructField(s); return err },
+		"parsePackageClause":    func(s string) error { _, err := parsePackageClause(s); return err },
+		"parseImportSpec":       func(s string) error { _, err := parseImportSpec(s); return err },
+		"parseImportBlock":      func(s string) error { _, err := parseImportBlock(s); return err },
+		"parseTypeSpec":         func(s string) error { _, err := parseTypeSpec(s); return err },
+		"parseArrayType":        func(s string) error { _, err := parseArrayType(s); return err },
+		"parseMapType":          func(s string) error { _, err := parseMapType(s); return err },
+		"parseGoStatement":      func(s string) error { _, err := parseGoStatement(s); return err },
+		"parseDeferStatement":   func(s string) error { _, err := parseDeferStatement(s); return err },
+		"parseReturnStatement":  func(s string) error { _, err := parseReturnStatement(s); return err },
+		"ClassifyStatement":     func(s string) error { _, _, err := ClassifyStatement(s); return err },
+		"parseShortVarDecl":     func(s string) error { _, err := parseShortVarDecl(s); return err },
+		"parseIfStatement":      func(s string) error { _, err := parseIfStatement(s); return err },
+		"parseSwitchStatement":  func(s string) error { _, err := parseSwitchStatement(s); return err },
+		"parseAssignment":       func(s string) error { _, err := parseAssignment(s); return err },
+		"parseIndexExpr":        func(s string) error { _, err := parseIndexExpr(s); return err },
+		"parseSliceExpr":        func(s string) error { _, err := parseSliceExpr(s); return err },
+		"parseCompositeLiteral": func(s string) error { _, err := parseCompositeLiteral(s); return err },
+		"parseChannelOp":        func(s string) error { _, err := parseChannelOp(s); return err },
+		"ParseGrammar":          func(s string) error { _, err := ParseGrammar(s); return err },
+	}
+
+	for name, parse := range parsers {
+		for _, input := range []string{"", " \t\n "} {
+			if err := parse(input); !errors.Is(err, errEmptyInput) {
+				t.Errorf("%s(%q) got error %v want errEmptyInput", name, input, err)
+			}
+		}
+	}
+
+	// validateIdentifier doesn't trim, so only "" counts as empty
+	if err := validateIdentifier(""); !errors.Is(err, errEmptyInput) {
+		t.Errorf(`validateIdentifier("") got error %v want errEmptyInput`, err)
+	}
+
+	t.Run("predicates follow the grammar", func(t *testing.T) {
+		if !isDigits("") {
+			t.Errorf(`isDigits("") = false want true`)
+		}
+		if isValidIdentifier("") || isValidInteger("") || isBoolean("") {
+			t.Errorf("an identifier, integer, or boolean can't be empty")
+		}
+	})
+}
+
+func TestParseErrorPos(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func(string) error
+		input   string
+		wantPos int
+	}{
+		{"sign only", signedNumberErr, "-", 1},
+		{"letter after sign", signedNumberErr, "+x", 1},
+		{"leading space", signedNumberErr, "   +x", 4},
+		{"not a number", signedNumberErr, "abc", 0},
+		{"no open paren", functionCallErr, "print", 5},
+		{"no close paren", functionCallErr, "print(1", 7},
+		{"missing argument", functionCallErr, "add(2,,3)", 6},
+		{"missing argument after space", functionCallErr, "add(2, , 3)", 7},
+		{"missing first argument", functionCallErr, "add(,3)", 4},
+		{"unbalanced argument", functionCallErr, "f(a, (b)", 5},
+		{"unterminated argument", functionCallErr, `f(a, b, "c)`, 8},
+		{"argument after multi-byte runes", functionCallErr, "f(\u00e9, \u00e9,, x)", 7},
+		{"rune positions", functionCallErr, "caf\u00e9", 4},
+		{"text after call", functionCallErr, "f(1)xyz", 4},
+		{"second call", functionCallErr, "f(1)(2)", 4},
+		{"missing name", functionCallErr, "  (x)", 2},
+		{"invalid name", functionCallErr, "1 2(3)", 0},
+		{"invalid qualified name", functionCallErr, "fmt.(x)", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse(tt.input)
+			pe, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("got %T (%v), want *ParseError", err, err)
+			}
+			if pe.Pos != tt.wantPos {
+				t.Errorf("Pos = %d, want %d (%v)", pe.Pos, tt.wantPos, err)
+			}
+		})
+	}
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	cause := errors.New("cause")
+	err := newParseError(3, "wrapped: %w", cause)
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(%v, cause) = false, want the cause kept", err)
+	}
+	if want := "wrapped: cause at position 3"; err.Error() != want {
+		t.Errorf("Error() = %q want %q", err, want)
+	}
+	if err := newParseError(0, "plain %d", 1); err.Unwrap() != nil {
+		t.Errorf("Unwrap() = %v, want nil without %%w", err.Unwrap())
+	}
+
+	_, callErr := parseFunctionCall("f(a, (b)")
+	var pe *ParseError
+	if !errors.As(callErr, &pe) || pe.Err == nil || !strings.Contains(pe.Err.Error(), "unclosed") {
+		t.Errorf("parseFunctionCall error %v should wrap the split error", callErr)
+	}
+}
+
+func signedNumberErr(s string) error { _, err := parseSignedNumber(s); return err }
+func functionCallErr(s string) error { _, err := parseFunctionCall(s); return err }
+
+func TestMatchesDigitSet(t *testing.T) {
+	const (
+		binary   = "01"
+		upperHex = "0123456789ABCDEF"
+	)
+	tests := []struct {
+		input, digits string
+		want          bool
+	}{
+		{"1010", binary, true},
+		{"1012", binary, false},
+		{"FF", upperHex, true},
+		{"DEADBEEF", upperHex, true},
+		{"ff", upperHex, false},
+		{"0x1F", upperHex, false},
+		{"", binary, true}, // zero occurrences
+		{"1", "", false},
+		{"\u00bd", "\u00bc\u00bd", true}, // multi-byte runes in the set
+	}
+
+	for _, tt := range tests {
+		if got := matchesDigitSet(tt.input, tt.digits); got != tt.want {
+			t.Errorf("matchesDigitSet(%q, %q) = %v, want %v", tt.input, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCall(t *testing.T) {
+	tests := []struct {
+		fc   FunctionCall
+		want string
+	}{
+		{FunctionCall{Name: "f"}, "f()"},
+		{FunctionCall{Name: "f", Arguments: []string{}}, "f()"},
+		{FunctionCall{Name: "neg", Arguments: []string{"x"}}, "neg(x)"},
+		{FunctionCall{Name: "add", Arguments: []string{"1", "2"}}, "add(1, 2)"},
+		{FunctionCall{Name: " fmt.Printf ", Arguments: []string{` "%d\n"`, " n ", "\tm"}}, `fmt.Printf("%d\n", n, m)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatCall(tt.fc); got != tt.want {
+				t.Errorf("FormatCall(%#v) = %q, want %q", tt.fc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFunctionCallLimited(t *testing.T) {
+	tests := []struct {
+		call    string
+		maxArgs int
+		wantErr bool
+	}{
+		{"f(a)", 3, false},       // under the limit
+		{"f(a, b, c)", 3, false}, // at the limit
+		{"f(a, b, c, d)", 3, true},
+		{"f()", 1, false},
+		{"f(a, b, c, d)", 0, false},  // unlimited
+		{"f(a, b, c, d)", -1, false}, // unlimited
+		{"f(g(1, 2, 3))", 1, false},  // nested arguments don't count
+		{"f(a", 3, true},             // parse errors still surface
+	}
+
+	for _, tt := range tests {
+		fc, err := ParseFunctionCallLimited(tt.call, tt.maxArgs)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFunctionCallLimited(%q, %d) error = %v, wantErr %v", tt.call, tt.maxArgs, err, tt.wantErr)
+			continue
+		}
+		if err == nil {
+			want, _ := parseFunctionCall(tt.call)
+			if !reflect.DeepEqual(fc, want) {
+				t.Errorf("ParseFunctionCallLimited(%q, %d) = %+v, want %+v", tt.call, tt.maxArgs, fc, want)
+			}
+		}
+	}
+}
+
+func TestParseFunctionCallSep(t *testing.T) {
+	tests := []struct {
+		call string
+		sep  rune
+		want []string
+	}{
+		{"max(1; 2; 3)", ';', []string{"1", "2", "3"}},
+		{"max(1; 2, 3)", ';', []string{"1", "2, 3"}}, // commas are literal
+		{"f(a,b; g(1; 2))", ';', []string{"a,b", "g(1; 2)"}},
+		{`f("x;y"; z;)`, ';', []string{`"x;y"`, "z"}},
+		{"f()", ';', []string{}},
+		{"sum(a b  c)", ' ', []string{"a", "b", "c"}},
+		{"sum( a )", ' ', []string{"a"}},
+		{"sum(f(1, 2) [x y])", ' ', []string{"f(1, 2)", "[x y]"}},
+		{"add(1, 2)", ',', []string{"1", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.call, func(t *testing.T) {
+			fc, err := ParseFunctionCallSep(tt.call, tt.sep)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fc.Arguments, tt.want) {
+				t.Errorf("arguments %q want %q", fc.Arguments, tt.want)
+			}
+		})
+	}
+
+	for _, call := range []string{"f(1;;2)", "f(;)", "f(1; (2)", "f(1)xyz", "(x)", "1 2(3)"} {
+		t.Run("error "+call, func(t *testing.T) {
+			if _, err := ParseFunctionCallSep(call, ';'); err == nil {
+				t.Errorf("ParseFunctionCallSep(%q, ';') expected an error", call)
+			}
+		})
+	}
+}
@@ -0,0 +1,561 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// EXPRESSIONS - Translating Go's expression grammar
+// ============================================================================
+//
+// Based on: https://go.dev/ref/spec#Expressions
+
+// ============================================================================
+// 1. INDEX AND SLICE EXPRESSIONS
+// ============================================================================
+// EBNF: PrimaryExpr = ... | PrimaryExpr Index | PrimaryExpr Slice .
+//       Index = "[" Expression "]" .
+//       Slice = "[" [ Expression ] ":" [ Expression ] "]" |
+//               "[" [ Expression ] ":" Expression ":" Expression "]" .
+
+type IndexExpr struct {
+	Base  string // the PrimaryExpr being indexed
+	Index string
+}
+
+type SliceExpr struct {
+	Base string
+	Low  string // optional
+	High string // optional, except in a full slice
+	Max  string // only in a full slice
+	Full bool   // three-index form a[low : high : max]
+}
+
+func parseIndexExpr(s string) (IndexExpr, error) {
+	if strings.TrimSpace(s) == "" {
+		return IndexExpr{}, errEmptyInput
+	}
+	base, inner, err := splitTrailingBrackets(s)
+	if err != nil {
+		return IndexExpr{}, err
+	}
+
+	parts, err := splitDelimitedList(inner, ':')
+	if err != nil {
+		return IndexExpr{}, err
+	}
+	if len(parts) > 1 {
+		return IndexExpr{}, fmt.Errorf("%q is a slice expression, not an index", s)
+	}
+	if len(parts) == 0 || parts[0] == "" {
+		return IndexExpr{}, fmt.Errorf("missing index in %q", s)
+	}
+	return IndexExpr{Base: base, Index: parts[0]}, nil
+}
+
+func parseSliceExpr(s string) (SliceExpr, error) {
+	if strings.TrimSpace(s) == "" {
+		return SliceExpr{}, errEmptyInput
+	}
+	base, inner, err := splitTrailingBrackets(s)
+	if err != nil {
+		return SliceExpr{}, err
+	}
+
+	parts, err := splitDelimitedList(inner, ':')
+	if err != nil {
+		return SliceExpr{}, err
+	}
+	switch len(parts) {
+	case 2:
+		return SliceExpr{Base: base, Low: parts[0], High: parts[1]}, nil
+	case 3:
+		if parts[1] == "" || parts[2] == "" {
+			return SliceExpr{}, fmt.Errorf("full slice %q needs both high and max", s)
+		}
+		return SliceExpr{Base: base, Low: parts[0], High: parts[1], Max: parts[2], Full: true}, nil
+	}
+	return SliceExpr{}, fmt.Errorf("%q is not a slice expression", s)
+}
+
+// splitTrailingBrackets splits "base[inner]" at the bracket pair that ends s,
+// so "m[k1][k2]" gives "m[k1]" and "k2", and "f(x)[i]" gives "f(x)" and "i".
+func splitTrailingBrackets(s string) (base, inner string, err error) {
+	s = strings.TrimSpace(s)
+	open := openingOfTrailingGroup(s)
+	if open == -1 || s[open] != '[' {
+		return "", "", fmt.Errorf("%q does not end in [...]", s)
+	}
+
+	base = strings.TrimSpace(s[:open])
+	if base == "" {
+		return "", "", fmt.Errorf("missing operand before [ in %q", s)
+	}
+	return base, s[open+1 : len(s)-1], nil
+}
+
+// openingOfTrailingGroup returns the index of the bracket that opens the
+// top-level group ending s, or -1 if s does not end in a balanced group.
+func openingOfTrailingGroup(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			end := matchingBracket(s, i)
+			if end == -1 {
+				return -1
+			}
+			if end == len(s)-1 {
+				return i
+			}
+			i = end
+		case '"', '\'', '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				return -1
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// Example usage:
+// parseIndexExpr("arr[i]")       // {Base: "arr", Index: "i"}
+// parseIndexExpr("m[k1][k2]")    // {Base: "m[k1]", Index: "k2"}
+// parseSliceExpr("arr[1:3]")     // {Base: "arr", Low: "1", High: "3"}
+// parseSliceExpr("arr[:n]")      // {Base: "arr", High: "n"}
+// parseSliceExpr("arr[1:3:5]")   // {Base: "arr", Low: "1", High: "3", Max: "5", Full: true}
+
+// ============================================================================
+// 2. BINARY EXPRESSIONS - Operator precedence
+// ============================================================================
+// EBNF: Expression = UnaryExpr | Expression binary_op Expression .
+//       binary_op = "||" | "&&" | rel_op | add_op | mul_op .
+//       rel_op = "==" | "!=" | "<" | "<=" | ">" | ">=" .
+//       add_op = "+" | "-" | "|" | "^" .
+//       mul_op = "*" | "/" | "%" | "<<" | ">>" | "&" | "&^" .
+//
+// The grammar alone is ambiguous; precedence resolves it. An expression
+// splits at its lowest-precedence top-level operator, and at the rightmost
+// one among equals because binary operators are left-associative. The
+// sign of an exponent, as in 1e-5, is part of the number, not an operator.
+
+// binaryOperators are matched longest first, so "&&" is never read as "&".
+var binaryOperators = []string{
+	"&&", "||", "==", "!=", "<=", ">=", "<<", ">>", "&^",
+	"+", "-", "*", "/", "%", "&", "|", "^", "<", ">",
+}
+
+// binaryPrecedence returns the precedence of a binary operator (5 binds
+// tightest, 1 loosest), or 0 if op is not one.
+func binaryPrecedence(op string) int {
+	switch op {
+	case "*", "/", "%", "<<", ">>", "&", "&^":
+		return 5
+	case "+", "-", "|", "^":
+		return 4
+	case "==", "!=", "<", "<=", ">", ">=":
+		return 3
+	case "&&":
+		return 2
+	case "||":
+		return 1
+	}
+	return 0
+}
+
+func splitBinaryExpr(s string) (left, op, right string, ok bool) {
+	splitAt, bestPrec := -1, 0
+	afterOperand := false // a binary operator needs an operand on its left
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			continue
+		case c == '(' || c == '[' || c == '{':
+			end := matchingBracket(s, i)
+			if end == -1 {
+				return "", "", "", false
+			}
+			i, afterOperand = end, true
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				return "", "", "", false
+			}
+			i, afterOperand = end, true
+			continue
+		case isDigit(rune(c)) && !afterOperand:
+			// a number, whose exponent may carry a sign: 1e-5, 0x1p+3
+			i, afterOperand = numberEnd(s, i)-1, true
+			continue
+		case isLetter(rune(c)) || isDigit(rune(c)) || c == '_' || c == '.' || c >= utf8.RuneSelf:
+			afterOperand = true
+			continue
+		}
+
+		candidate, _ := longestMatch(s[i:], binaryOperators)
+		width := max(len(candidate), 1)
+		next := i + width
+
+		switch {
+		case strings.HasPrefix(s[i:], "<-"):
+			width = 2 // receive operator, always unary
+		case candidate == "" || !afterOperand:
+			// unary operator (-x, !ok, *p, &v, ^m) or other punctuation
+		case next < len(s) && s[next] == '=' && binaryPrecedence(candidate) != 3:
+			width++ // assignment operator such as "+=" or "<<="
+		default:
+			if prec := binaryPrecedence(candidate); splitAt == -1 || prec <= bestPrec {
+				splitAt, bestPrec, op = i, prec, candidate
+			}
+		}
+		i += width - 1
+		afterOperand = false
+	}
+
+	if splitAt == -1 {
+		return "", "", "", false
+	}
+	left = strings.TrimSpace(s[:splitAt])
+	right = strings.TrimSpace(s[splitAt+len(op):])
+	if left == "" || right == "" {
+		return "", "", "", false
+	}
+	return left, op, right, true
+}
+
+// numberEnd returns the index just past the numeric literal starting at
+// s[start]. A sign belongs to the literal only right after its exponent
+// letter: 'e' or 'E' in decimal, 'p' or 'P' in hex, where 'e' is a digit.
+func numberEnd(s string, start int) int {
+	exponent := "eE"
+	if strings.HasPrefix(s[start:], "0x") || strings.HasPrefix(s[start:], "0X") {
+		exponent = "pP"
+	}
+	i := start
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case isLetter(rune(c)) || isDigit(rune(c)) || c == '_' || c == '.':
+		case (c == '+' || c == '-') && strings.IndexByte(exponent, s[i-1]) != -1:
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// Example usage:
+// splitBinaryExpr("a + b * c")    // "a", "+", "b * c", true
+// splitBinaryExpr("(a+b)*c")      // "(a+b)", "*", "c", true
+// splitBinaryExpr("a - b - c")    // "a - b", "-", "c", true (left-associative)
+// splitBinaryExpr("f(x)")         // "", "", "", false
+// splitBinaryExpr("x + 1e-5")     // "x", "+", "1e-5", true
+
+// ============================================================================
+// 3. COMPOSITE LITERALS
+// ============================================================================
+// EBNF: CompositeLit = LiteralType LiteralValue .
+//       LiteralValue = "{" [ ElementList [ "," ] ] "}" .
+//       ElementList = KeyedElement { "," KeyedElement } .
+//       KeyedElement = [ Key ":" ] Element .
+//
+// Nested LiteralValues such as the {1} in [][]int{{1}} are kept as element
+// text; pass them back through with their element type to go deeper.
+
+type KeyedElement struct {
+	Key   string // optional: field name, map key, or index
+	Value string
+}
+
+type CompositeLiteral struct {
+	Type     string
+	Elements []KeyedElement
+}
+
+func parseCompositeLiteral(s string) (CompositeLiteral, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return CompositeLiteral{}, errEmptyInput
+	}
+	open := openingOfTrailingGroup(s)
+	if open == -1 || s[open] != '{' {
+		return CompositeLiteral{}, fmt.Errorf("composite literal %q must end in {...}", s)
+	}
+
+	typ := strings.TrimSpace(s[:open])
+	if _, err := parseTypeSpec(typ); err != nil {
+		return CompositeLiteral{}, fmt.Errorf("invalid literal type: %w", err)
+	}
+
+	items, err := splitDelimited(s[open+1:len(s)-1], ',', true)
+	if err != nil {
+		return CompositeLiteral{}, err
+	}
+
+	lit := CompositeLiteral{Type: typ, Elements: []KeyedElement{}}
+	for _, item := range items {
+		parts, err := splitDelimitedList(item, ':')
+		if err != nil {
+			return CompositeLiteral{}, err
+		}
+		var elem KeyedElement
+		switch len(parts) {
+		case 1:
+			elem.Value = parts[0]
+		case 2:
+			elem.Key, elem.Value = parts[0], parts[1]
+			if elem.Key == "" {
+				return CompositeLiteral{}, fmt.Errorf("missing key in element %q", item)
+			}
+		default:
+			return CompositeLiteral{}, fmt.Errorf("invalid element %q", item)
+		}
+		if elem.Value == "" {
+			return CompositeLiteral{}, fmt.Errorf("missing value in element %q", item)
+		}
+		lit.Elements = append(lit.Elements, elem)
+	}
+	return lit, nil
+}
+
+// Example usage:
+// parseCompositeLiteral("[]int{1, 2, 3}")     // {Type: "[]int", Elements: [{Value: 1} {Value: 2} {Value: 3}]}
+// parseCompositeLiteral("Point{X: 1, Y: 2}")  // {Type: "Point", Elements: [{X 1} {Y 2}]}
+// parseCompositeLiteral("[][]int{{1},{2}}")   // {Type: "[][]int", Elements: [{Value: "{1}"} {Value: "{2}"}]}
+
+// ============================================================================
+// 4. CHANNEL OPERATIONS
+// ============================================================================
+// EBNF: SendStmt = Channel "<-" Expression .
+//       Channel = Expression .
+//       UnaryExpr = PrimaryExpr | unary_op UnaryExpr .   (unary_op includes "<-")
+//
+// "<-" is one token, so "a < -b" is a comparison, not a channel operation.
+
+type ChannelOp struct {
+	Direction ChanDir // ChanSend or ChanRecv
+	Channel   string
+	Value     string // Send: the value sent; Recv: the assigned variables, if any
+}
+
+func parseChannelOp(s string) (ChannelOp, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ChannelOp{}, errEmptyInput
+	}
+	idx := indexTopLevel(s, "<-")
+	if idx == -1 {
+		return ChannelOp{}, fmt.Errorf("not a channel operation: %q", s)
+	}
+
+	left := strings.TrimSpace(s[:idx])
+	right := strings.TrimSpace(s[idx+len("<-"):])
+	if right == "" {
+		return ChannelOp{}, fmt.Errorf("missing operand after <- in %q", s)
+	}
+
+	switch {
+	case left == "":
+		// <-ch
+		return ChannelOp{Direction: ChanRecv, Channel: right}, nil
+	case strings.HasSuffix(left, "="):
+		// v := <-ch, v, ok = <-ch; but not a == <-ch or x += <-ch
+		last := len(left) - 1
+		op, width := classifyAssignOp(left, last)
+		if op != ":=" && op != "=" {
+			return ChannelOp{}, fmt.Errorf("receive must be assigned with = or :=, not %s, in %q", op, s)
+		}
+		vars := strings.TrimSpace(left[:last+width-len(op)])
+		if vars == "" {
+			return ChannelOp{}, fmt.Errorf("missing variable before assignment in %q", s)
+		}
+		return ChannelOp{Direction: ChanRecv, Channel: right, Value: vars}, nil
+	}
+	// ch <- v
+	return ChannelOp{Direction: ChanSend, Channel: left, Value: right}, nil
+}
+
+// Example usage:
+// parseChannelOp("ch <- v")     // {Direction: send, Channel: "ch", Value: "v"}
+// parseChannelOp("v := <-ch")   // {Direction: recv, Channel: "ch", Value: "v"}
+// parseChannelOp("<-ch")        // {Direction: recv, Channel: "ch"}
+// parseChannelOp("a < b")       // error: not a channel operation
+// parseChannelOp("a == <-ch")   // error: receive must be assigned with = or :=
+
+// ============================================================================
+// 5. DISPATCHING AN EXPRESSION
+// ============================================================================
+// EBNF: Expression = UnaryExpr | Expression binary_op Expression .
+//       UnaryExpr = PrimaryExpr | unary_op UnaryExpr .
+//       unary_op = "+" | "-" | "!" | "^" | "*" | "&" | "<-" .
+//       PrimaryExpr = Operand | PrimaryExpr Index | PrimaryExpr Slice |
+//                     PrimaryExpr Arguments | ... .
+//
+// parseExpression picks a parser by shape, loosest binding first: a binary
+// operator splits the expression and both sides recurse; then a unary
+// operator; then the trailing group decides between call, index or slice,
+// composite literal, and type assertion. What's left must be an operand:
+// an identifier, a qualified identifier, or a literal, returned as a
+// RawExpr.
+
+type BinaryExpr struct {
+	Left  Node
+	Op    string
+	Right Node
+}
+
+type UnaryExpr struct {
+	Op      string
+	Operand Node
+}
+
+var unaryOperators = []string{"<-", "+", "-", "!", "^", "*", "&"}
+
+func parseExpression(s string) (Node, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errEmptyInput
+	}
+
+	if left, op, right, ok := splitBinaryExpr(s); ok {
+		l, err := parseExpression(left)
+		if err != nil {
+			return nil, err
+		}
+		r, err := parseExpression(right)
+		if err != nil {
+			return nil, err
+		}
+		return BinaryExpr{Left: l, Op: op, Right: r}, nil
+	}
+
+	for _, op := range unaryOperators {
+		if rest, ok := strings.CutPrefix(s, op); ok {
+			operand, err := parseExpression(rest)
+			if err != nil {
+				return nil, err
+			}
+			return UnaryExpr{Op: op, Operand: operand}, nil
+		}
+	}
+
+	if open := openingOfTrailingGroup(s); open != -1 {
+		switch {
+		case open == 0 && s[0] == '(':
+			return parseExpression(s[1 : len(s)-1])
+		case s[open] == '(' && strings.HasSuffix(strings.TrimSpace(s[:open]), "."):
+			ta, err := parseTypeAssertion(s)
+			if err == nil && ta.IsTypeSwitch {
+				err = fmt.Errorf("use of .(type) outside type switch in %q", s)
+			}
+			return asNode(ta, err)
+		case s[open] == '(':
+			return asNode(parseFunctionCall(s))
+		case s[open] == '[' && indexTopLevel(s[open+1:len(s)-1], ":") != -1:
+			return asNode(parseSliceExpr(s))
+		case s[open] == '[':
+			return asNode(parseIndexExpr(s))
+		case s[open] == '{':
+			return asNode(parseCompositeLiteral(s))
+		}
+	}
+
+	if !isOperand(s) {
+		return nil, fmt.Errorf("unsupported expression %q", s)
+	}
+	return RawExpr(s), nil
+}
+
+// asNode returns a parser's result as a Node, or a nil Node on error.
+func asNode[T Node](n T, err error) (Node, error) {
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// isOperand reports whether s is an identifier, a qualified identifier
+// such as pkg.Name, or a numeric, string, or rune literal.
+func isOperand(s string) bool {
+	switch {
+	case isNumericLiteral(s):
+		return true
+	case s[0] == '"' || s[0] == '`' || s[0] == '\'':
+		_, err := strconv.Unquote(s)
+		return err == nil
+	}
+	for _, part := range strings.Split(s, ".") {
+		if !isValidIdentifier(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// Example usage:
+// parseExpression("a + b*c")         // BinaryExpr{a + BinaryExpr{b * c}}
+// parseExpression("-x")              // UnaryExpr{-, x}
+// parseExpression("f(x, y)")         // FunctionCall{f, [x y]}
+// parseExpression("xs[1:]")          // SliceExpr{...}
+// parseExpression("Point{1, 2}")     // CompositeLiteral{...}
+// parseExpression("a b")             // error: unsupported expression
+
+// ============================================================================
+// 6. TYPE ASSERTIONS
+// ============================================================================
+// EBNF: PrimaryExpr = ... | PrimaryExpr TypeAssertion .
+//       TypeAssertion = "." "(" Type ")" .
+//
+// The assertion is the trailing group, so "f(x).(T)" and "m[k].(int)"
+// split after their own brackets. A type switch guard "x.(type)" is the one
+// place "type" stands in for a type; it parses with IsTypeSwitch set and
+// an empty Type, and parseExpression rejects it.
+
+type TypeAssertion struct {
+	X            string // the PrimaryExpr being asserted
+	Type         string // empty for .(type)
+	IsTypeSwitch bool
+}
+
+func parseTypeAssertion(s string) (TypeAssertion, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return TypeAssertion{}, errEmptyInput
+	}
+	open := openingOfTrailingGroup(s)
+	if open == -1 || s[open] != '(' {
+		return TypeAssertion{}, fmt.Errorf("%q does not end in .(T)", s)
+	}
+	x, ok := strings.CutSuffix(strings.TrimSpace(s[:open]), ".")
+	if !ok {
+		return TypeAssertion{}, fmt.Errorf("%q is not a type assertion: missing . before (", s)
+	}
+	if x = strings.TrimSpace(x); x == "" {
+		return TypeAssertion{}, fmt.Errorf("missing operand before .( in %q", s)
+	}
+
+	typ := strings.TrimSpace(s[open+1 : len(s)-1])
+	if typ == "type" {
+		return TypeAssertion{X: x, IsTypeSwitch: true}, nil
+	}
+	if err := validateDeclType(typ); err != nil {
+		return TypeAssertion{}, fmt.Errorf("invalid asserted type %q: %w", typ, err)
+	}
+	return TypeAssertion{X: x, Type: typ}, nil
+}
+
+// Example usage:
+// parseTypeAssertion("x.(int)")        // {X: "x", Type: "int"}
+// parseTypeAssertion("v.(*os.File)")   // {X: "v", Type: "*os.File"}
+// parseTypeAssertion("f(x).(T)")       // {X: "f(x)", Type: "T"}
+// parseTypeAssertion("x.(List[int])")  // {X: "x", Type: "List[int]"}
+// parseTypeAssertion("x.(type)")       // {X: "x", IsTypeSwitch: true}
+// parseTypeAssertion("x.()")           // error: invalid asserted type
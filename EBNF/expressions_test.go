@@ -0,0 +1,306 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIndexExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		want  IndexExpr
+	}{
+		{"arr[i]", IndexExpr{Base: "arr", Index: "i"}},
+		{"m[k1][k2]", IndexExpr{Base: "m[k1]", Index: "k2"}},
+		{"f(x)[i]", IndexExpr{Base: "f(x)", Index: "i"}},
+		{"m[\"a]\"]", IndexExpr{Base: "m", Index: "\"a]\""}},
+		{"grid[y][x+1]", IndexExpr{Base: "grid[y]", Index: "x+1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseIndexExpr(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"arr", "[i]", "arr[]", "arr[1:2]", "arr[i", "f(x)"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseIndexExpr(input); err == nil {
+				t.Errorf("parseIndexExpr(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseSliceExpr(t *testing.T) {
+	tests := []struct {
+		input string
+		want  SliceExpr
+	}{
+		{"arr[1:3]", SliceExpr{Base: "arr", Low: "1", High: "3"}},
+		{"arr[:n]", SliceExpr{Base: "arr", High: "n"}},
+		{"arr[i:]", SliceExpr{Base: "arr", Low: "i"}},
+		{"arr[:]", SliceExpr{Base: "arr"}},
+		{"arr[1:3:5]", SliceExpr{Base: "arr", Low: "1", High: "3", Max: "5", Full: true}},
+		{"arr[:3:5]", SliceExpr{Base: "arr", High: "3", Max: "5", Full: true}},
+		{"m[k][a[0]:len(s)]", SliceExpr{Base: "m[k]", Low: "a[0]", High: "len(s)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSliceExpr(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"arr[i]", "arr[1::5]", "arr[1:3:]", "arr[1:2:3:4]", "[1:2]"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseSliceExpr(input); err == nil {
+				t.Errorf("parseSliceExpr(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestSplitBinaryExpr(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantLeft  string
+		wantOp    string
+		wantRight string
+	}{
+		{"a+b*c", "a", "+", "b*c"},
+		{"a * b + c", "a * b", "+", "c"},
+		{"(a+b)*c", "(a+b)", "*", "c"},
+		{"a - b - c", "a - b", "-", "c"},
+		{"-a + b", "-a", "+", "b"},
+		{"a * -b", "a", "*", "-b"},
+		{"x == y && z", "x == y", "&&", "z"},
+		{"a || b && c", "a", "||", "b && c"},
+		{"a &^ b", "a", "&^", "b"},
+		{"x << 2 | y", "x << 2", "|", "y"},
+		{"a <= b", "a", "<=", "b"},
+		{`"a+b" + c`, `"a+b"`, "+", "c"},
+		{"f(a+b) - m[i*j]", "f(a+b)", "-", "m[i*j]"},
+		{"<-ch + 1", "<-ch", "+", "1"},
+		{"*p * 2", "*p", "*", "2"},
+		{"x + 1e-5", "x", "+", "1e-5"},
+		{"2.5e+3 - y", "2.5e+3", "-", "y"},
+		{"1E-2*3", "1E-2", "*", "3"},
+		{"0x1p-2 + 1", "0x1p-2", "+", "1"},
+		{"0x1e-5", "0x1e", "-", "5"}, // e is a hex digit, not an exponent
+		{"a1e-5", "a1e", "-", "5"},   // an identifier, not a number
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			left, op, right, ok := splitBinaryExpr(tt.input)
+			if !ok {
+				t.Fatalf("splitBinaryExpr(%q) found no operator", tt.input)
+			}
+			if left != tt.wantLeft || op != tt.wantOp || right != tt.wantRight {
+				t.Errorf("got %q %q %q want %q %q %q", left, op, right, tt.wantLeft, tt.wantOp, tt.wantRight)
+			}
+		})
+	}
+
+	for _, input := range []string{"x", "f(a + b)", "-x", "!ok", "<-ch", "x += 1", "a = b", `"a + b"`, "a +", "1e-5", "2.5e+3", "0x1p-2"} {
+		t.Run("no split "+input, func(t *testing.T) {
+			if l, o, r, ok := splitBinaryExpr(input); ok {
+				t.Errorf("splitBinaryExpr(%q) = %q %q %q, want no split", input, l, o, r)
+			}
+		})
+	}
+}
+
+func TestParseCompositeLiteral(t *testing.T) {
+	tests := []struct {
+		input string
+		want  CompositeLiteral
+	}{
+		{"[]int{1, 2, 3}", CompositeLiteral{Type: "[]int", Elements: []KeyedElement{{Value: "1"}, {Value: "2"}, {Value: "3"}}}},
+		{"T{a, b}", CompositeLiteral{Type: "T", Elements: []KeyedElement{{Value: "a"}, {Value: "b"}}}},
+		{"Point{X: 1, Y: 2}", CompositeLiteral{Type: "Point", Elements: []KeyedElement{{"X", "1"}, {"Y", "2"}}}},
+		{"[][]int{{1},{2}}", CompositeLiteral{Type: "[][]int", Elements: []KeyedElement{{Value: "{1}"}, {Value: "{2}"}}}},
+		{`map[string][]int{"a": {1, 2}, "b:c": nil,}`, CompositeLiteral{Type: "map[string][]int",
+			Elements: []KeyedElement{{`"a"`, "{1, 2}"}, {`"b:c"`, "nil"}}}},
+		{"pkg.Config{}", CompositeLiteral{Type: "pkg.Config", Elements: []KeyedElement{}}},
+		{"[...]string{2: \"c\"}", CompositeLiteral{Type: "[...]string", Elements: []KeyedElement{{"2", `"c"`}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseCompositeLiteral(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"Point{X: 1", "Point", "{1, 2}", "T{a,,b}", "T{: 1}", "T{X: }", "1x{}"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseCompositeLiteral(input); err == nil {
+				t.Errorf("parseCompositeLiteral(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseChannelOp(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ChannelOp
+	}{
+		{"ch <- v", ChannelOp{Direction: ChanSend, Channel: "ch", Value: "v"}},
+		{"results[i] <- f(x)", ChannelOp{Direction: ChanSend, Channel: "results[i]", Value: "f(x)"}},
+		{"v := <-ch", ChannelOp{Direction: ChanRecv, Channel: "ch", Value: "v"}},
+		{"v, ok = <-ch", ChannelOp{Direction: ChanRecv, Channel: "ch", Value: "v, ok"}},
+		{"v:=<-ch", ChannelOp{Direction: ChanRecv, Channel: "ch", Value: "v"}},
+		{"m[k] = <-ch", ChannelOp{Direction: ChanRecv, Channel: "ch", Value: "m[k]"}},
+		{"<-ch", ChannelOp{Direction: ChanRecv, Channel: "ch"}},
+		{"<-done", ChannelOp{Direction: ChanRecv, Channel: "done"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseChannelOp(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"a < b", "a < -b", "x <= y", "ch <-", `f("<-")`, ":= <-ch", "= <-ch",
+		"a == <-ch", "x >= <-ch", "x += <-ch", "x != <-ch", "x <<= <-ch",
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseChannelOp(input); err == nil {
+				t.Errorf("parseChannelOp(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantKind  string
+		wantKinds []string // Walk order, when the shape matters
+	}{
+		{"x", "expr", nil},
+		{"fmt.Println", "expr", nil},
+		{"0xFF", "expr", nil},
+		{"1.5e3", "expr", nil},
+		{"1e-5", "expr", nil},
+		{"2.5e+3", "expr", nil},
+		{"x + 1e-5", "binary", []string{"binary", "expr", "expr"}},
+		{"-1e-5 * x", "binary", []string{"binary", "unary", "expr", "expr"}},
+		{`"a + b"`, "expr", nil},
+		{"'x'", "expr", nil},
+		{"f(x, y)", "call", nil},
+		{"arr[i]", "index", nil},
+		{"xs[1:n]", "slice", nil},
+		{"Point{X: 1, Y: 2}", "composite", nil},
+		{"-x", "unary", []string{"unary", "expr"}},
+		{"<-ch", "unary", []string{"unary", "expr"}},
+		{"a + b*c", "binary", []string{"binary", "expr", "binary", "expr", "expr"}},
+		{"(a + b) * c", "binary", []string{"binary", "binary", "expr", "expr", "expr"}},
+		{"len(xs) > 0 && !done", "binary", []string{"binary", "binary", "call", "expr", "expr", "unary", "expr"}},
+		{"m[k] == T{1}", "binary", []string{"binary", "index", "expr", "expr", "composite", "expr"}},
+		{"((x))", "expr", nil},
+		{"v.(*os.File)", "assert", []string{"assert", "expr"}},
+		{"err.(T) != nil", "binary", []string{"binary", "assert", "expr", "expr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseExpression(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Kind() != tt.wantKind {
+				t.Errorf("kind got %q want %q", got.Kind(), tt.wantKind)
+			}
+			if tt.wantKinds != nil && !reflect.DeepEqual(kinds(got), tt.wantKinds) {
+				t.Errorf("walk got %v want %v", kinds(got), tt.wantKinds)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "a b", "1x", "f(", "a + ", `"unterminated`, "arr[]", "1 ! 2", "x.(type)"} {
+		t.Run("error "+input, func(t *testing.T) {
+			node, err := parseExpression(input)
+			if err == nil {
+				t.Errorf("parseExpression(%q) = %v, expected an error", input, node)
+			}
+			if node != nil {
+				t.Errorf("parseExpression(%q) returned node %v with its error", input, node)
+			}
+		})
+	}
+}
+
+func TestParseTypeAssertion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  TypeAssertion
+	}{
+		{"x.(int)", TypeAssertion{X: "x", Type: "int"}},
+		{"v.(*os.File)", TypeAssertion{X: "v", Type: "*os.File"}},
+		{"f(x).(T)", TypeAssertion{X: "f(x)", Type: "T"}},
+		{"m[k].([]byte)", TypeAssertion{X: "m[k]", Type: "[]byte"}},
+		{"r.(interface{ Close() error })", TypeAssertion{X: "r", Type: "interface{ Close() error }"}},
+		{"a.b.(fmt.Stringer)", TypeAssertion{X: "a.b", Type: "fmt.Stringer"}},
+		{"x.(List[int])", TypeAssertion{X: "x", Type: "List[int]"}},
+		{"v.(*pkg.Pair[K, V])", TypeAssertion{X: "v", Type: "*pkg.Pair[K, V]"}},
+		{"x.(type)", TypeAssertion{X: "x", IsTypeSwitch: true}},
+		{"f(a, b).( type )", TypeAssertion{X: "f(a, b)", IsTypeSwitch: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTypeAssertion(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"x",          // no assertion
+		"f(int)",     // a call, not an assertion
+		".(int)",     // no operand
+		"x.()",       // no type
+		"x.(1int)",   // invalid type
+		"x.(List[])", // no type arguments
+		"x.(T",       // unclosed
+		"x.(T)[0]",   // the trailing group is an index
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseTypeAssertion(input); err == nil {
+				t.Errorf("parseTypeAssertion(%q) expected an error", input)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// GO.MOD - Require directives
+// ============================================================================
+// EBNF: RequireDirective = "require" ( RequireSpec | "(" newline { RequireSpec } ")" newline ) .
+//       RequireSpec = ModulePath Version newline .
+//
+// See https://go.dev/ref/mod#go-mod-file-require. parseModRequire reads a
+// single-line directive or one line from the body of a require block. The
+// go command marks requirements no package in the main module imports
+// with a trailing "// indirect" comment, which sets Indirect.
+
+type ModRequire struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+func parseModRequire(line string) (ModRequire, error) {
+	if strings.TrimSpace(line) == "" {
+		return ModRequire{}, errEmptyInput
+	}
+	code := StripLineComment(line)
+	comment := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[len(code):]), "//"))
+
+	fields := strings.Fields(code)
+	if len(fields) > 0 && fields[0] == "require" {
+		fields = fields[1:]
+	}
+	switch len(fields) {
+	case 0:
+		return ModRequire{}, fmt.Errorf("missing module path in %q", strings.TrimSpace(line))
+	case 1:
+		return ModRequire{}, fmt.Errorf("missing version for %s", fields[0])
+	case 2:
+	default:
+		return ModRequire{}, fmt.Errorf("unexpected %q after version in %q", fields[2], strings.TrimSpace(line))
+	}
+
+	req := ModRequire{Path: fields[0], Version: fields[1]}
+	if strings.HasPrefix(req.Path, `"`) {
+		path, err := strconv.Unquote(req.Path)
+		if err != nil {
+			return ModRequire{}, fmt.Errorf("invalid quoted module path %s", req.Path)
+		}
+		req.Path = path
+	}
+	if !isValidSemVer(req.Version) {
+		return ModRequire{}, fmt.Errorf("invalid version %q for %s", req.Version, req.Path)
+	}
+
+	// "// indirect", possibly followed by "; other notes"
+	note, _, _ := strings.Cut(comment, ";")
+	req.Indirect = strings.TrimSpace(note) == "indirect"
+	return req, nil
+}
+
+// Example usage:
+// parseModRequire("require github.com/foo/bar v1.2.3")      // {github.com/foo/bar v1.2.3 false}
+// parseModRequire("\tgolang.org/x/text v0.14.0 // indirect")  // {golang.org/x/text v0.14.0 true}
+// parseModRequire("require github.com/foo/bar")             // error: missing version
+
+// ============================================================================
+// SEMANTIC VERSIONS
+// ============================================================================
+// EBNF: SemVer     = "v" Major "." Minor "." Patch [ "-" PreRelease ] [ "+" Build ] .
+//       Major      = NumericId .  (Minor and Patch likewise)
+//       PreRelease = PreId { "." PreId } .
+//       PreId      = NumericId | AlnumId .
+//       Build      = AlnumId { "." AlnumId } .
+//       NumericId  = "0" | ( "1" … "9" ) { Digit } .
+//       AlnumId    = ( Letter | Digit | "-" ) { Letter | Digit | "-" } .
+//
+// This is https://semver.org with the leading "v" Go requires in go.mod
+// and in module tags, so "1.2.3" is rejected. Unlike the go command,
+// which also takes the shorthands "v1" and "v1.2" in some places, all
+// three numbers are required. A numeric pre-release identifier may not
+// have leading zeros ("rc.01"); build identifiers may ("+build.007").
+
+func isValidSemVer(s string) bool {
+	rest, ok := strings.CutPrefix(s, "v")
+	if !ok {
+		return false
+	}
+	rest, build, hasBuild := strings.Cut(rest, "+")
+	core, pre, hasPre := strings.Cut(rest, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	for _, part := range parts {
+		if !isValidDecimal(part) {
+			return false
+		}
+	}
+	if hasPre && !validSemVerIds(pre, true) {
+		return false
+	}
+	return !hasBuild || validSemVerIds(build, false)
+}
+
+// validSemVerIds checks a dot-separated pre-release or build list. With
+// strictNumeric, an all-digit identifier must not have leading zeros.
+func validSemVerIds(s string, strictNumeric bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+		for _, c := range id {
+			if !isLetter(c) && !isDigit(c) && c != '-' {
+				return false
+			}
+		}
+		if strictNumeric && isDigits(id) && !isValidDecimal(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// Example usage:
+// isValidSemVer("v1.2.3")          // true
+// isValidSemVer("v1.2.3-rc.1")     // true
+// isValidSemVer("v1.0.0+build.5")  // true
+// isValidSemVer("1.2.3")           // false (no "v")
+// isValidSemVer("v1.2")            // false
+// isValidSemVer("v1.2.3.4")        // false
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+func TestParseModRequire(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ModRequire
+	}{
+		{"require github.com/foo/bar v1.2.3", ModRequire{Path: "github.com/foo/bar", Version: "v1.2.3"}},
+		{"\tgithub.com/foo/bar v1.2.3", ModRequire{Path: "github.com/foo/bar", Version: "v1.2.3"}},
+		{"golang.org/x/text v0.14.0 // indirect", ModRequire{Path: "golang.org/x/text", Version: "v0.14.0", Indirect: true}},
+		{"golang.org/x/text v0.14.0 //indirect; pinned", ModRequire{Path: "golang.org/x/text", Version: "v0.14.0", Indirect: true}},
+		{"example.com/m v1.0.0 // keep in sync with tools", ModRequire{Path: "example.com/m", Version: "v1.0.0"}},
+		{"example.com/m v0.0.0-20240101120000-abcdef123456", ModRequire{Path: "example.com/m", Version: "v0.0.0-20240101120000-abcdef123456"}},
+		{"example.com/old v2.0.1+incompatible", ModRequire{Path: "example.com/old", Version: "v2.0.1+incompatible"}},
+		{`require "example.com/quoted" v1.0.0-rc.1`, ModRequire{Path: "example.com/quoted", Version: "v1.0.0-rc.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseModRequire(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"",
+		"require",                            // no path
+		"require github.com/foo/bar",         // missing version
+		"github.com/foo/bar // indirect",     // missing version
+		"github.com/foo/bar 1.2.3",           // no "v"
+		"github.com/foo/bar v1.2",            // not three parts
+		"github.com/foo/bar v1.02.3",         // leading zero
+		"github.com/foo/bar v1.2.3-",         // empty prerelease
+		"github.com/foo/bar v1.2.3 v1.2.4",   // extra field
+		"require (",                          // block opener
+		`require "github.com/foo/bar v1.2.3`, // unterminated quote
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseModRequire(input); err == nil {
+				t.Errorf("parseModRequire(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestIsValidSemVer(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		// releases
+		{"v1.2.3", true},
+		{"v0.0.0", true},
+		{"v10.20.30", true},
+		// pre-releases
+		{"v1.2.3-rc.1", true},
+		{"v1.0.0-alpha", true},
+		{"v1.0.0-alpha.beta-2.0", true},
+		{"v0.0.0-20240101120000-abcdef123456", true},
+		// build metadata
+		{"v1.0.0+build.5", true},
+		{"v1.0.0+build.007", true}, // leading zeros are fine in build ids
+		{"v1.0.0-rc.1+exp.sha.5114f85", true},
+		{"v2.0.1+incompatible", true},
+		// invalid
+		{"", false},
+		{"1.2.3", false},  // no "v"
+		{"V1.2.3", false}, // capital V
+		{"v1", false},
+		{"v1.2", false},
+		{"v1.2.3.4", false},
+		{"v01.2.3", false},
+		{"v1.2.-3", false},
+		{"v1.2.3-", false},
+		{"v1.2.3-rc..1", false},
+		{"v1.2.3-rc.01", false},
+		{"v1.2.3+", false},
+		{"v1.2.3+build_5", false},
+		{"v1.2.3 ", false},
+		{"va.b.c", false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidSemVer(tt.input); got != tt.want {
+			t.Errorf("isValidSemVer(%q) = %v want %v", tt.input, got, tt.want)
+		}
+	}
+}
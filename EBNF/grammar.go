@@ -0,0 +1,1236 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// GRAMMAR ENGINE - EBNF as data
+// ============================================================================
+//
+// The examples in ebnf_go_examples.go translate each rule into Go by hand.
+// This file goes one step further: ParseGrammar reads the EBNF text itself,
+// so a rule can be inspected (DescribeRule, FirstSet), checked (Validate),
+// or run against input (Match) without writing a parser for it.
+//
+// EBNF: Production = production_name "=" [ Expression ] "." .
+//       Expression = Term { "|" Term } .
+//       Term = Factor { Factor } .
+//       Factor = production_name | token [ "…" token ] | Group | Option | Repetition .
+//       Group = "(" Expression ")" .
+//       Option = "[" Expression "]" .
+//       Repetition = "{" Expression "}" .
+
+// exampleGrammar collects the EBNF rules used throughout the examples.
+const exampleGrammar = `
+Boolean      = "true" | "false" .
+Sign         = "+" | "-" .
+SignedNumber = [ Sign ] Number .
+Number       = Digit { Digit } .
+Digits       = { Digit } .
+Digit        = "0" … "9" .
+Letter       = "a" … "z" | "A" … "Z" .
+Identifier   = letter { letter | unicode_digit | "_" } .
+letter       = "a" … "z" | "A" … "Z" | "_" .
+IntLit       = DecimalLit | BinaryLit | OctalLit | HexLit .
+DecimalLit   = ( "1" … "9" ) { DecimalDigit } | "0" .
+BinaryLit    = "0" ( "b" | "B" ) BinaryDigit { BinaryDigit } .
+OctalLit     = "0" ( "o" | "O" ) OctalDigit { OctalDigit } .
+HexLit       = "0" ( "x" | "X" ) HexDigit { HexDigit } .
+DecimalDigit = "0" … "9" .
+BinaryDigit  = "0" | "1" .
+OctalDigit   = "0" … "7" .
+HexDigit     = "0" … "9" | "A" … "F" | "a" … "f" .
+`
+
+// builtinClasses are the character classes the Go spec uses without
+// defining them in EBNF (see https://go.dev/ref/spec#Characters).
+var builtinClasses = map[string]func(rune) bool{
+	"newline":        func(c rune) bool { return c == '\n' },
+	"unicode_char":   func(c rune) bool { return c != '\n' },
+	"unicode_letter": unicode.IsLetter,
+	"unicode_digit":  func(c rune) bool { return unicode.Is(unicode.Nd, c) },
+}
+
+// ebnfExpr is one node of a parsed EBNF expression.
+type ebnfExpr interface {
+	ebnfNode()
+}
+
+type (
+	ebnfName        struct{ Name string }   // production_name
+	ebnfToken       struct{ Value string }  // "literal"
+	ebnfRange       struct{ Lo, Hi string } // "a" … "z"
+	ebnfGroup       struct{ Body ebnfExpr } // ( ... )
+	ebnfOption      struct{ Body ebnfExpr } // [ ... ]
+	ebnfRepetition  struct{ Body ebnfExpr } // { ... }
+	ebnfSequence    []ebnfExpr              // a b c
+	ebnfAlternative []ebnfExpr              // a | b | c
+)
+
+func (ebnfName) ebnfNode()        {}
+func (ebnfToken) ebnfNode()       {}
+func (ebnfRange) ebnfNode()       {}
+func (ebnfGroup) ebnfNode()       {}
+func (ebnfOption) ebnfNode()      {}
+func (ebnfRepetition) ebnfNode()  {}
+func (ebnfSequence) ebnfNode()    {}
+func (ebnfAlternative) ebnfNode() {}
+
+type Production struct {
+	Name string
+	Expr ebnfExpr // nil for an empty production "X = ."
+}
+
+type Grammar struct {
+	Productions []*Production // in source order
+	byName      map[string]*Production
+
+	mu            sync.Mutex               // guards compiled and leftRecursive
+	compiled      map[string]*compiledRule // see CompileRule
+	leftRecursive map[string]bool          // rules compiled with seedAndGrow
+}
+
+// Rule returns the production called name, or nil if there is none.
+func (g *Grammar) Rule(name string) *Production {
+	return g.byName[name]
+}
+
+// ============================================================================
+// 1. PARSING - EBNF text to Grammar
+// ============================================================================
+
+type grammarToken struct {
+	kind string // "name", "token", or the symbol itself: = . | ( ) [ ] { } …
+	text string // name or decoded token value
+	pos  int    // byte offset in the source
+}
+
+func tokenizeGrammar(src string) ([]grammarToken, error) {
+	var toks []grammarToken
+	for i := 0; i < len(src); {
+		c, width := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += width
+		case c == '"':
+			value, rest, err := parseEBNFTerminal(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("at offset %d: %w", i, err)
+			}
+			toks = append(toks, grammarToken{kind: "token", text: value, pos: i})
+			i = len(src) - len(rest)
+		case c == '`':
+			end := skipQuoted(src, i)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated token at offset %d", i)
+			}
+			toks = append(toks, grammarToken{kind: "token", text: src[i+1 : end], pos: i})
+			i = end + 1
+		case c == '…':
+			toks = append(toks, grammarToken{kind: "…", pos: i})
+			i += width
+		case strings.HasPrefix(src[i:], "..."):
+			toks = append(toks, grammarToken{kind: "…", pos: i})
+			i += len("...")
+		case strings.ContainsRune("=.|()[]{}", c):
+			toks = append(toks, grammarToken{kind: string(c), pos: i})
+			i += width
+		case isLetter(c) || c == '_':
+			name := leadingIdentifier(src[i:])
+			toks = append(toks, grammarToken{kind: "name", text: name, pos: i})
+			i += len(name)
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+// parseEBNFTerminal reads the double-quoted terminal at the start of s,
+// decoding backslash escapes as in a Go string literal, and returns its
+// value and the input after the closing quote.
+func parseEBNFTerminal(s string) (value, rest string, err error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", s, fmt.Errorf("terminal must start with a double quote")
+	}
+
+	var b strings.Builder
+	in := s[1:]
+	for {
+		switch {
+		case in == "" || in[0] == '\n':
+			return "", s, fmt.Errorf("unterminated terminal %s", strings.SplitN(s, "\n", 2)[0])
+		case in[0] == '"':
+			return b.String(), in[1:], nil
+		}
+
+		if in[0] != '\\' {
+			c, width := utf8.DecodeRuneInString(in)
+			b.WriteRune(c)
+			in = in[width:]
+			continue
+		}
+		c, width, err := decodeEscape(in)
+		switch {
+		case err != nil:
+			return "", s, fmt.Errorf("invalid escape in terminal: %w", err)
+		case in[1] == '\'':
+			return "", s, fmt.Errorf(`invalid escape in terminal: \' is only valid in rune literals`)
+		case in[1] == 'x' || isOctalDigit(rune(in[1])):
+			b.WriteByte(byte(c)) // byte escape, possibly half of a UTF-8 sequence
+		default:
+			b.WriteRune(c)
+		}
+		in = in[width:]
+	}
+}
+
+// parseEBNFRange reads a character range, two double-quoted terminals
+// separated by "…" or "...", from the start of s and returns its inclusive
+// bounds and the input after the second terminal. Both terminals must be
+// a single rune and lo may not be above hi.
+func parseEBNFRange(s string) (lo, hi rune, rest string, err error) {
+	loText, rest, err := parseEBNFTerminal(s)
+	if err != nil {
+		return 0, 0, s, err
+	}
+	after := strings.TrimLeftFunc(rest, unicode.IsSpace)
+	if cut, ok := strings.CutPrefix(after, "…"); ok {
+		after = cut
+	} else if cut, ok := strings.CutPrefix(after, "..."); ok {
+		after = cut
+	} else {
+		return 0, 0, s, fmt.Errorf("expected … after %s", strconv.Quote(loText))
+	}
+	hiText, rest, err := parseEBNFTerminal(strings.TrimLeftFunc(after, unicode.IsSpace))
+	if err != nil {
+		return 0, 0, s, err
+	}
+	if lo, hi, err = runeRange(loText, hiText); err != nil {
+		return 0, 0, s, err
+	}
+	return lo, hi, rest, nil
+}
+
+// runeRange checks the bounds of a range factor, which the parser and
+// parseEBNFRange share so Match never sees a range it can't compare.
+func runeRange(loText, hiText string) (lo, hi rune, err error) {
+	for _, text := range []string{loText, hiText} {
+		if c, width := utf8.DecodeRuneInString(text); width != len(text) || (c == utf8.RuneError && width <= 1) {
+			return 0, 0, fmt.Errorf("range bound %s is not a single character", strconv.Quote(text))
+		}
+	}
+	lo, _ = utf8.DecodeRuneInString(loText)
+	hi, _ = utf8.DecodeRuneInString(hiText)
+	if lo > hi {
+		return 0, 0, fmt.Errorf("range %s … %s is reversed", strconv.Quote(loText), strconv.Quote(hiText))
+	}
+	return lo, hi, nil
+}
+
+// Example usage:
+// parseEBNFTerminal(`"abc" . rest`)  // "abc", " . rest", nil
+// parseEBNFTerminal(`"say \"hi\""`)  // `say "hi"`, "", nil
+// parseEBNFTerminal(`"oops`)         // error: unterminated terminal
+// parseEBNFRange(`"0" … "9" .`)      // '0', '9', " .", nil
+// parseEBNFRange(`"a"..."z"`)        // 'a', 'z', "", nil
+// parseEBNFRange(`"z" … "a"`)        // error: range "z" … "a" is reversed
+// parseEBNFRange(`"ab" … "z"`)       // error: range bound "ab" is not a single character
+
+type grammarParser struct {
+	toks []grammarToken
+	pos  int
+	end  int // source length, reported for errors at end of input
+}
+
+func (p *grammarParser) peek() grammarToken {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return grammarToken{kind: "EOF", pos: p.end}
+}
+
+func (p *grammarParser) expect(kind string) (grammarToken, error) {
+	tok := p.peek()
+	if tok.kind != kind {
+		return tok, fmt.Errorf("expected %s, found %s at offset %d", kind, tok.kind, tok.pos)
+	}
+	p.pos++
+	return tok, nil
+}
+
+// ParseGrammar parses a list of EBNF productions. Productions may appear in
+// any order and may reference each other; Match reports names that are
+// neither defined nor a builtin character class.
+func ParseGrammar(src string) (*Grammar, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, errEmptyInput
+	}
+	toks, err := tokenizeGrammar(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &grammarParser{toks: toks, end: len(src)}
+	g := &Grammar{byName: map[string]*Production{}}
+	for p.peek().kind != "EOF" {
+		prod, err := p.parseProduction()
+		if err != nil {
+			return nil, err
+		}
+		if g.byName[prod.Name] != nil {
+			return nil, fmt.Errorf("production %s redefined", prod.Name)
+		}
+		g.Productions = append(g.Productions, prod)
+		g.byName[prod.Name] = prod
+	}
+	return g, nil
+}
+
+func (p *grammarParser) parseProduction() (*Production, error) {
+	name, err := p.expect("name")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("="); err != nil {
+		return nil, err
+	}
+
+	prod := &Production{Name: name.text}
+	if p.peek().kind != "." {
+		if prod.Expr, err = p.parseExpression(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect("."); err != nil {
+		return nil, err
+	}
+	return prod, nil
+}
+
+func (p *grammarParser) parseExpression() (ebnfExpr, error) {
+	var alt ebnfAlternative
+	for {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		alt = append(alt, term)
+		if p.peek().kind != "|" {
+			break
+		}
+		p.pos++
+	}
+	if len(alt) == 1 {
+		return alt[0], nil
+	}
+	return alt, nil
+}
+
+func (p *grammarParser) parseTerm() (ebnfExpr, error) {
+	var seq ebnfSequence
+	for {
+		switch p.peek().kind {
+		case "name", "token", "(", "[", "{":
+		default:
+			if len(seq) == 0 {
+				tok := p.peek()
+				return nil, fmt.Errorf("expected expression, found %s at offset %d", tok.kind, tok.pos)
+			}
+			if len(seq) == 1 {
+				return seq[0], nil
+			}
+			return seq, nil
+		}
+
+		factor, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, factor)
+	}
+}
+
+func (p *grammarParser) parseFactor() (ebnfExpr, error) {
+	tok := p.peek()
+	p.pos++
+
+	switch tok.kind {
+	case "name":
+		return ebnfName{Name: tok.text}, nil
+	case "token":
+		if p.peek().kind != "…" {
+			return ebnfToken{Value: tok.text}, nil
+		}
+		p.pos++
+		hi, err := p.expect("token")
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := runeRange(tok.text, hi.text); err != nil {
+			return nil, fmt.Errorf("%w at offset %d", err, tok.pos)
+		}
+		return ebnfRange{Lo: tok.text, Hi: hi.text}, nil
+	}
+
+	// Group, Option, or Repetition
+	closer := map[string]string{"(": ")", "[": "]", "{": "}"}[tok.kind]
+	body, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(closer); err != nil {
+		return nil, err
+	}
+	switch tok.kind {
+	case "(":
+		return ebnfGroup{Body: body}, nil
+	case "[":
+		return ebnfOption{Body: body}, nil
+	}
+	return ebnfRepetition{Body: body}, nil
+}
+
+// Example usage:
+// g, _ := ParseGrammar(`Boolean = "true" | "false" .`)
+// g.Rule("Boolean").Expr  // ebnfAlternative{ebnfToken{"true"}, ebnfToken{"false"}}
+
+// ============================================================================
+// 2. MATCHING - Running a rule against input
+// ============================================================================
+// Each node returns every position where it could stop matching, so
+// alternatives and repetitions backtrack naturally. Input is matched
+// character by character; whitespace is never skipped implicitly.
+
+// Match reports whether the whole of input matches the named rule. The
+// rule is compiled on first use, so later calls skip walking the tree.
+func (g *Grammar) Match(rule, input string) (bool, error) {
+	if g.Rule(rule) == nil {
+		return false, fmt.Errorf("undefined production %s", rule)
+	}
+
+	g.mu.Lock()
+	r := g.compileRule(rule)
+	g.mu.Unlock()
+
+	ends, err := r.match(&matchState{input: input}, 0)
+	if err != nil {
+		return false, err
+	}
+	for _, end := range ends {
+		if end == len(input) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchExpr returns the sorted, de-duplicated end positions of every way e
+// can match input starting at pos. It interprets the tree directly; Match
+// uses the compiled form below, which must give the same answers. Only the
+// compiled form copes with left recursion; here it never returns.
+func (g *Grammar) matchExpr(e ebnfExpr, input string, pos int) ([]int, error) {
+	switch e := e.(type) {
+	case nil:
+		return []int{pos}, nil
+
+	case ebnfName:
+		if class, ok := builtinClasses[e.Name]; ok {
+			c, width := utf8.DecodeRuneInString(input[pos:])
+			if width > 0 && class(c) {
+				return []int{pos + width}, nil
+			}
+			return nil, nil
+		}
+		prod := g.Rule(e.Name)
+		if prod == nil {
+			return nil, fmt.Errorf("undefined production %s", e.Name)
+		}
+		return g.matchExpr(prod.Expr, input, pos)
+
+	case ebnfToken:
+		if strings.HasPrefix(input[pos:], e.Value) {
+			return []int{pos + len(e.Value)}, nil
+		}
+		return nil, nil
+
+	case ebnfRange:
+		lo, _ := utf8.DecodeRuneInString(e.Lo)
+		hi, _ := utf8.DecodeRuneInString(e.Hi)
+		c, width := utf8.DecodeRuneInString(input[pos:])
+		if width > 0 && c >= lo && c <= hi {
+			return []int{pos + width}, nil
+		}
+		return nil, nil
+
+	case ebnfGroup:
+		return g.matchExpr(e.Body, input, pos)
+
+	case ebnfOption:
+		ends, err := g.matchExpr(e.Body, input, pos)
+		if err != nil {
+			return nil, err
+		}
+		return uniquePositions(append(ends, pos)), nil
+
+	case ebnfRepetition:
+		// Breadth-first closure; seen stops zero-width bodies looping forever
+		seen := map[int]bool{pos: true}
+		ends := []int{pos}
+		for frontier := []int{pos}; len(frontier) > 0; {
+			var next []int
+			for _, p := range frontier {
+				more, err := g.matchExpr(e.Body, input, p)
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range more {
+					if !seen[m] {
+						seen[m] = true
+						next = append(next, m)
+					}
+				}
+			}
+			ends = append(ends, next...)
+			frontier = next
+		}
+		return uniquePositions(ends), nil
+
+	case ebnfSequence:
+		positions := []int{pos}
+		for _, item := range e {
+			var next []int
+			for _, p := range positions {
+				ends, err := g.matchExpr(item, input, p)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, ends...)
+			}
+			positions = uniquePositions(next)
+			if len(positions) == 0 {
+				return nil, nil
+			}
+		}
+		return positions, nil
+
+	case ebnfAlternative:
+		var all []int
+		for _, option := range e {
+			ends, err := g.matchExpr(option, input, pos)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, ends...)
+		}
+		return uniquePositions(all), nil
+	}
+	return nil, fmt.Errorf("unknown expression %T", e)
+}
+
+func uniquePositions(positions []int) []int {
+	sort.Ints(positions)
+	out := positions[:0]
+	for i, p := range positions {
+		if i == 0 || p != positions[i-1] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ============================================================================
+// 3. COMPILING - Closures instead of a tree walk
+// ============================================================================
+// compileExpr turns each node into a matcher closure once, with the type
+// switch, rule lookups, and range decoding done up front. Compiling a rule
+// compiles everything it references; references go through a compiledRule
+// cell that is registered before its body is built, so recursive rules
+// work. Once compileRule returns, the cells are never written again, so
+// matchers run without holding g.mu; what one Match call needs to
+// remember lives in its matchState.
+//
+// A left-recursive rule such as "Expr = Expr "+" Term | Term ." would call
+// itself at the same position forever, so those rules, and only those, are
+// wrapped in seedAndGrow. The recursive call at the starting position is
+// answered from a seed, first empty, and the body is rerun with the ends
+// it found as the new seed until no new end appears. Each round can only
+// add ends, and there are at most len(input)+1 of them, so it stops.
+
+type matcher func(st *matchState, pos int) ([]int, error)
+
+type compiledRule struct {
+	match matcher
+}
+
+// matchState is the input of one Match call and the seeds of the
+// left-recursive rules it is growing, keyed by rule and position.
+type matchState struct {
+	input   string
+	growing map[growKey][]int
+}
+
+type growKey struct {
+	rule *compiledRule
+	pos  int
+}
+
+// CompileRule builds the matcher for the named rule and the rules it uses,
+// so the first Match doesn't pay for it. Match compiles on demand anyway.
+func (g *Grammar) CompileRule(name string) error {
+	if g.Rule(name) == nil {
+		return fmt.Errorf("undefined production %s", name)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.compileRule(name)
+	return nil
+}
+
+// compileRule returns the cached matcher for name, building it if needed.
+// The caller must hold g.mu.
+func (g *Grammar) compileRule(name string) *compiledRule {
+	if r := g.compiled[name]; r != nil {
+		return r
+	}
+	if g.compiled == nil {
+		g.compiled = map[string]*compiledRule{}
+		g.leftRecursive = g.leftRecursiveRules()
+	}
+
+	r := &compiledRule{}
+	g.compiled[name] = r
+	if prod := g.Rule(name); prod != nil {
+		r.match = g.compileExpr(prod.Expr)
+		if g.leftRecursive[name] {
+			r.match = seedAndGrow(r, r.match)
+		}
+	} else {
+		r.match = func(*matchState, int) ([]int, error) {
+			return nil, fmt.Errorf("undefined production %s", name)
+		}
+	}
+	return r
+}
+
+func (g *Grammar) compileExpr(e ebnfExpr) matcher {
+	switch e := e.(type) {
+	case nil:
+		return func(_ *matchState, pos int) ([]int, error) { return []int{pos}, nil }
+
+	case ebnfName:
+		if class, ok := builtinClasses[e.Name]; ok {
+			return func(st *matchState, pos int) ([]int, error) {
+				c, width := utf8.DecodeRuneInString(st.input[pos:])
+				if width > 0 && class(c) {
+					return []int{pos + width}, nil
+				}
+				return nil, nil
+			}
+		}
+		r := g.compileRule(e.Name)
+		return func(st *matchState, pos int) ([]int, error) { return r.match(st, pos) }
+
+	case ebnfToken:
+		return func(st *matchState, pos int) ([]int, error) {
+			if strings.HasPrefix(st.input[pos:], e.Value) {
+				return []int{pos + len(e.Value)}, nil
+			}
+			return nil, nil
+		}
+
+	case ebnfRange:
+		lo, _ := utf8.DecodeRuneInString(e.Lo)
+		hi, _ := utf8.DecodeRuneInString(e.Hi)
+		return func(st *matchState, pos int) ([]int, error) {
+			c, width := utf8.DecodeRuneInString(st.input[pos:])
+			if width > 0 && c >= lo && c <= hi {
+				return []int{pos + width}, nil
+			}
+			return nil, nil
+		}
+
+	case ebnfGroup:
+		return g.compileExpr(e.Body)
+
+	case ebnfOption:
+		body := g.compileExpr(e.Body)
+		return func(st *matchState, pos int) ([]int, error) {
+			ends, err := body(st, pos)
+			if err != nil {
+				return nil, err
+			}
+			return uniquePositions(append(ends, pos)), nil
+		}
+
+	case ebnfRepetition:
+		body := g.compileExpr(e.Body)
+		return func(st *matchState, pos int) ([]int, error) {
+			seen := map[int]bool{pos: true}
+			ends := []int{pos}
+			for frontier := []int{pos}; len(frontier) > 0; {
+				var next []int
+				for _, p := range frontier {
+					more, err := body(st, p)
+					if err != nil {
+						return nil, err
+					}
+					for _, m := range more {
+						if !seen[m] {
+							seen[m] = true
+							next = append(next, m)
+						}
+					}
+				}
+				ends = append(ends, next...)
+				frontier = next
+			}
+			return uniquePositions(ends), nil
+		}
+
+	case ebnfSequence:
+		items := make([]matcher, len(e))
+		for i, item := range e {
+			items[i] = g.compileExpr(item)
+		}
+		return func(st *matchState, pos int) ([]int, error) {
+			positions := []int{pos}
+			for _, item := range items {
+				var next []int
+				for _, p := range positions {
+					ends, err := item(st, p)
+					if err != nil {
+						return nil, err
+					}
+					next = append(next, ends...)
+				}
+				positions = uniquePositions(next)
+				if len(positions) == 0 {
+					return nil, nil
+				}
+			}
+			return positions, nil
+		}
+
+	case ebnfAlternative:
+		options := make([]matcher, len(e))
+		for i, option := range e {
+			options[i] = g.compileExpr(option)
+		}
+		return func(st *matchState, pos int) ([]int, error) {
+			var all []int
+			for _, option := range options {
+				ends, err := option(st, pos)
+				if err != nil {
+					return nil, err
+				}
+				all = append(all, ends...)
+			}
+			return uniquePositions(all), nil
+		}
+	}
+	return func(*matchState, int) ([]int, error) { return nil, fmt.Errorf("unknown expression %T", e) }
+}
+
+// seedAndGrow runs body to a fixed point for a left-recursive rule r, as
+// described above. A call for r at a position it is already growing gets
+// a copy of the current seed.
+func seedAndGrow(r *compiledRule, body matcher) matcher {
+	return func(st *matchState, pos int) ([]int, error) {
+		key := growKey{rule: r, pos: pos}
+		if seed, ok := st.growing[key]; ok {
+			return slices.Clone(seed), nil
+		}
+		if st.growing == nil {
+			st.growing = map[growKey][]int{}
+		}
+		defer delete(st.growing, key)
+
+		var seed []int
+		for {
+			st.growing[key] = seed
+			ends, err := body(st, pos)
+			if err != nil {
+				return nil, err
+			}
+			ends = uniquePositions(append(ends, seed...))
+			if len(ends) == len(seed) {
+				return seed, nil
+			}
+			seed = ends
+		}
+	}
+}
+
+// leftRecursiveRules returns the productions that can reach themselves
+// through the names that start their expression, the cycles Validate
+// reports.
+func (g *Grammar) leftRecursiveRules() map[string]bool {
+	nullable := g.nullableRules()
+	recursive := map[string]bool{}
+	for _, prod := range g.Productions {
+		seen := map[string]bool{}
+		stack := leftNames(prod.Expr, nullable, nil)
+		for len(stack) > 0 {
+			name := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if name == prod.Name {
+				recursive[name] = true
+				break
+			}
+			if seen[name] || g.Rule(name) == nil {
+				continue
+			}
+			seen[name] = true
+			stack = leftNames(g.Rule(name).Expr, nullable, stack)
+		}
+	}
+	return recursive
+}
+
+// Example usage:
+// g, _ := ParseGrammar(exampleGrammar)
+// g.CompileRule("IntLit")          // optional: warm the cache
+// g.Match("IntLit", "0xFF")        // true, nil
+// g.Match("Identifier", "123var")  // false, nil
+// g, _ = ParseGrammar(`Expr = Expr "+" Term | Term . Term = "0" … "9" .`)
+// g.Match("Expr", "1+2+3")         // true, nil (left recursion grows from Term)
+
+// ============================================================================
+// 4. DESCRIBING - A text tree of a rule's structure
+// ============================================================================
+
+// DescribeRule renders the named rule as an indented ASCII tree, one node
+// per line, so the shape of alternations, groups, options, and repetitions
+// is visible at a glance.
+func DescribeRule(g *Grammar, name string) (string, error) {
+	prod := g.Rule(name)
+	if prod == nil {
+		return "", fmt.Errorf("undefined production %s", name)
+	}
+
+	var b strings.Builder
+	b.WriteString(name + "\n")
+	if prod.Expr != nil {
+		describeExpr(&b, prod.Expr, "", true)
+	}
+	return b.String(), nil
+}
+
+func describeExpr(b *strings.Builder, e ebnfExpr, indent string, last bool) {
+	branch, childIndent := "|-- ", indent+"|   "
+	if last {
+		branch, childIndent = "`-- ", indent+"    "
+	}
+
+	var label string
+	var children []ebnfExpr
+	switch e := e.(type) {
+	case ebnfName:
+		label = e.Name
+	case ebnfToken:
+		label = strconv.Quote(e.Value)
+	case ebnfRange:
+		label = "range " + strconv.Quote(e.Lo) + " … " + strconv.Quote(e.Hi)
+	case ebnfGroup:
+		label, children = "group", []ebnfExpr{e.Body}
+	case ebnfOption:
+		label, children = "option", []ebnfExpr{e.Body}
+	case ebnfRepetition:
+		label, children = "repetition", []ebnfExpr{e.Body}
+	case ebnfSequence:
+		label, children = "sequence", e
+	case ebnfAlternative:
+		label, children = "alternation", e
+	}
+
+	b.WriteString(indent + branch + label + "\n")
+	for i, child := range children {
+		describeExpr(b, child, childIndent, i == len(children)-1)
+	}
+}
+
+// Example usage:
+// DescribeRule(g, "Boolean")
+//
+//	Boolean
+//	`-- alternation
+//	    |-- "true"
+//	    `-- "false"
+
+// ============================================================================
+// 5. SERIALIZING - Grammar back to EBNF text
+// ============================================================================
+// String writes canonical EBNF: one production per line, single spaces
+// between factors, and every token re-quoted with strconv.Quote. Parsing the
+// result gives back an identical Grammar, so String is also a normalizing
+// pretty-printer.
+
+func (g *Grammar) String() string {
+	var b strings.Builder
+	for _, prod := range g.Productions {
+		b.WriteString(prod.String() + "\n")
+	}
+	return b.String()
+}
+
+func (p *Production) String() string {
+	if p.Expr == nil {
+		return p.Name + " = ."
+	}
+	return p.Name + " = " + formatExpr(p.Expr) + " ."
+}
+
+func formatExpr(e ebnfExpr) string {
+	switch e := e.(type) {
+	case ebnfName:
+		return e.Name
+	case ebnfToken:
+		return strconv.Quote(e.Value)
+	case ebnfRange:
+		return strconv.Quote(e.Lo) + " … " + strconv.Quote(e.Hi)
+	case ebnfGroup:
+		return "( " + formatExpr(e.Body) + " )"
+	case ebnfOption:
+		return "[ " + formatExpr(e.Body) + " ]"
+	case ebnfRepetition:
+		return "{ " + formatExpr(e.Body) + " }"
+	case ebnfSequence:
+		return joinExprs(e, " ")
+	case ebnfAlternative:
+		return joinExprs(e, " | ")
+	}
+	panic(fmt.Sprintf("formatExpr: unexpected %T", e))
+}
+
+func joinExprs(exprs []ebnfExpr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = formatExpr(e)
+	}
+	return strings.Join(parts, sep)
+}
+
+// CanonicalizeGrammar re-emits src for diffing: String's spacing and
+// quoting, productions sorted by name so moving a rule doesn't show up,
+// and parentheses dropped around a single factor, as in "( Digit )",
+// where they change nothing. Two grammars that differ only in those ways
+// canonicalize to the same bytes. Source order is deliberately not kept;
+// use String for that.
+func CanonicalizeGrammar(src string) (string, error) {
+	g, err := ParseGrammar(src)
+	if err != nil {
+		return "", err
+	}
+
+	prods := make([]*Production, len(g.Productions))
+	for i, prod := range g.Productions {
+		prods[i] = &Production{Name: prod.Name, Expr: ungroupFactors(prod.Expr)}
+	}
+	sort.Slice(prods, func(i, j int) bool { return prods[i].Name < prods[j].Name })
+
+	var b strings.Builder
+	for _, prod := range prods {
+		b.WriteString(prod.String() + "\n")
+	}
+	return b.String(), nil
+}
+
+// ungroupFactors returns a copy of e without groups whose body is a
+// single name, token, range, option, or repetition.
+func ungroupFactors(e ebnfExpr) ebnfExpr {
+	switch e := e.(type) {
+	case ebnfGroup:
+		body := ungroupFactors(e.Body)
+		switch body.(type) {
+		case ebnfSequence, ebnfAlternative:
+			return ebnfGroup{Body: body}
+		}
+		return body
+	case ebnfOption:
+		return ebnfOption{Body: ungroupFactors(e.Body)}
+	case ebnfRepetition:
+		return ebnfRepetition{Body: ungroupFactors(e.Body)}
+	case ebnfSequence:
+		out := make(ebnfSequence, len(e))
+		for i, item := range e {
+			out[i] = ungroupFactors(item)
+		}
+		return out
+	case ebnfAlternative:
+		out := make(ebnfAlternative, len(e))
+		for i, option := range e {
+			out[i] = ungroupFactors(option)
+		}
+		return out
+	}
+	return e
+}
+
+// Example usage:
+// g, _ := ParseGrammar(`Number=Digit{Digit}.  Digit="0"..."9".`)
+// g.String()
+// // Number = Digit { Digit } .
+// // Digit = "0" … "9" .
+// CanonicalizeGrammar(`Number=(Digit){Digit}.  Digit="0"..."9".`)
+// // Digit = "0" … "9" .
+// // Number = Digit { Digit } .
+
+// ============================================================================
+// 6. VALIDATING - Catching broken grammars before Match
+// ============================================================================
+// Match only notices an undefined production when it reaches it. Left
+// recursion such as "Expr = Expr "+" Term | Term ." Match handles (see
+// section 3), but matchExpr and a recursive-descent parser written by hand
+// from the rule would recurse without consuming input, so Validate reports
+// it too. A rule is left-recursive when it can reach itself through the
+// names that can start its expression, directly or via other rules; names
+// that may match the empty string let the names after them start the
+// expression too.
+
+// Validate reports the first undefined reference, in source order, or the
+// first cycle of left recursion, written as the chain of rules.
+func (g *Grammar) Validate() error {
+	for _, prod := range g.Productions {
+		for _, name := range referencedNames(prod.Expr, nil) {
+			if _, ok := builtinClasses[name]; !ok && g.Rule(name) == nil {
+				return fmt.Errorf("production %s: undefined production %s", prod.Name, name)
+			}
+		}
+	}
+
+	nullable := g.nullableRules()
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			return fmt.Errorf("left recursion: %s", strings.Join(append(path[start:], name), " -> "))
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, next := range leftNames(g.Rule(name).Expr, nullable, nil) {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, prod := range g.Productions {
+		if err := visit(prod.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// referencedNames appends every production name used in e, in order.
+func referencedNames(e ebnfExpr, names []string) []string {
+	switch e := e.(type) {
+	case ebnfName:
+		return append(names, e.Name)
+	case ebnfGroup:
+		return referencedNames(e.Body, names)
+	case ebnfOption:
+		return referencedNames(e.Body, names)
+	case ebnfRepetition:
+		return referencedNames(e.Body, names)
+	case ebnfSequence:
+		for _, item := range e {
+			names = referencedNames(item, names)
+		}
+	case ebnfAlternative:
+		for _, option := range e {
+			names = referencedNames(option, names)
+		}
+	}
+	return names
+}
+
+// nullableRules returns the productions that can match the empty string,
+// growing the set until it stops changing.
+func (g *Grammar) nullableRules() map[string]bool {
+	nullable := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, prod := range g.Productions {
+			if !nullable[prod.Name] && isNullable(prod.Expr, nullable) {
+				nullable[prod.Name] = true
+				changed = true
+			}
+		}
+	}
+	return nullable
+}
+
+func isNullable(e ebnfExpr, nullable map[string]bool) bool {
+	switch e := e.(type) {
+	case nil, ebnfOption, ebnfRepetition:
+		return true
+	case ebnfName:
+		return nullable[e.Name]
+	case ebnfToken:
+		return e.Value == ""
+	case ebnfGroup:
+		return isNullable(e.Body, nullable)
+	case ebnfSequence:
+		for _, item := range e {
+			if !isNullable(item, nullable) {
+				return false
+			}
+		}
+		return true
+	case ebnfAlternative:
+		for _, option := range e {
+			if isNullable(option, nullable) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// leftNames appends the defined production names that e can start with
+// before consuming any input. Builtin classes always consume a character.
+func leftNames(e ebnfExpr, nullable map[string]bool, names []string) []string {
+	switch e := e.(type) {
+	case ebnfName:
+		if _, ok := builtinClasses[e.Name]; !ok && !slices.Contains(names, e.Name) {
+			names = append(names, e.Name)
+		}
+	case ebnfGroup:
+		return leftNames(e.Body, nullable, names)
+	case ebnfOption:
+		return leftNames(e.Body, nullable, names)
+	case ebnfRepetition:
+		return leftNames(e.Body, nullable, names)
+	case ebnfSequence:
+		for _, item := range e {
+			names = leftNames(item, nullable, names)
+			if !isNullable(item, nullable) {
+				break
+			}
+		}
+	case ebnfAlternative:
+		for _, option := range e {
+			names = leftNames(option, nullable, names)
+		}
+	}
+	return names
+}
+
+// Example usage:
+// g, _ := ParseGrammar(`Expr = Expr "+" Term | Term . Term = "x" .`)
+// g.Validate()  // error: left recursion: Expr -> Expr
+// g, _ = ParseGrammar(`A = B "x" . B = [ "y" ] A .`)
+// g.Validate()  // error: left recursion: A -> B -> A
+// g, _ = ParseGrammar(`Number = Digit { Digit } .`)
+// g.Validate()  // error: production Number: undefined production Digit
+
+// ============================================================================
+// 7. FIRST SETS - What a rule can start with
+// ============================================================================
+// FIRST(A) is the set of terminals that can begin a match of A, the table
+// a predictive parser uses to pick an alternative from the next input.
+// It collects the terminals at the left edge of the rule and of every rule
+// reachable there, looking past items that can match the empty string.
+// Options and repetitions may be skipped, so they add their own FIRST set
+// and let the next item in a sequence contribute too.
+//
+// Keys are token values ("true"), single characters for a range ("a" …
+// "c" gives "a", "b", "c"), and the name of a builtin character class
+// ("unicode_letter"). "" is in the set when the rule can match nothing.
+
+func FirstSet(g *Grammar, ruleName string) (map[string]bool, error) {
+	prod := g.Rule(ruleName)
+	if prod == nil {
+		return nil, fmt.Errorf("undefined production %s", ruleName)
+	}
+
+	nullable := g.nullableRules()
+	first := map[string]bool{}
+	visited := map[string]bool{ruleName: true}
+	var add func(e ebnfExpr) error
+	add = func(e ebnfExpr) error {
+		switch e := e.(type) {
+		case ebnfName:
+			if _, ok := builtinClasses[e.Name]; ok {
+				first[e.Name] = true
+				return nil
+			}
+			prod := g.Rule(e.Name)
+			if prod == nil {
+				return fmt.Errorf("undefined production %s", e.Name)
+			}
+			if !visited[e.Name] {
+				visited[e.Name] = true
+				return add(prod.Expr)
+			}
+		case ebnfToken:
+			if e.Value != "" {
+				first[e.Value] = true
+			}
+		case ebnfRange:
+			lo, _ := utf8.DecodeRuneInString(e.Lo)
+			hi, _ := utf8.DecodeRuneInString(e.Hi)
+			for c := lo; c <= hi; c++ {
+				first[string(c)] = true
+			}
+		case ebnfGroup:
+			return add(e.Body)
+		case ebnfOption:
+			return add(e.Body)
+		case ebnfRepetition:
+			return add(e.Body)
+		case ebnfSequence:
+			for _, item := range e {
+				if err := add(item); err != nil {
+					return err
+				}
+				if !isNullable(item, nullable) {
+					break
+				}
+			}
+		case ebnfAlternative:
+			for _, option := range e {
+				if err := add(option); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := add(prod.Expr); err != nil {
+		return nil, err
+	}
+	if nullable[ruleName] {
+		first[""] = true
+	}
+	return first, nil
+}
+
+// Example usage:
+// g, _ := ParseGrammar(exampleGrammar)
+// FirstSet(g, "Boolean")       // {"true", "false"}
+// FirstSet(g, "SignedNumber")  // {"+", "-", "0", …, "9"}
+// FirstSet(g, "Digits")        // {"", "0", …, "9"}
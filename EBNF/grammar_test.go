@@ -0,0 +1,597 @@
+package main
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mustParseExampleGrammar(t *testing.T) *Grammar {
+	t.Helper()
+	g, err := ParseGrammar(exampleGrammar)
+	if err != nil {
+		t.Fatalf("ParseGrammar(exampleGrammar): %v", err)
+	}
+	return g
+}
+
+func TestParseGrammar(t *testing.T) {
+	t.Run("parses the example grammar in order", func(t *testing.T) {
+		g := mustParseExampleGrammar(t)
+		if got := g.Productions[0].Name; got != "Boolean" {
+			t.Errorf("first production got %q want %q", got, "Boolean")
+		}
+		if g.Rule("IntLit") == nil {
+			t.Errorf("expected an IntLit production")
+		}
+	})
+	t.Run("accepts ... as a range separator", func(t *testing.T) {
+		g, err := ParseGrammar(`Digit = "0" ... "9" .`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := ebnfRange{Lo: "0", Hi: "9"}
+		if got := g.Rule("Digit").Expr; got != want {
+			t.Errorf("got %#v want %#v", got, want)
+		}
+	})
+
+	errorCases := []string{
+		`A = "a"`,
+		`A = "a .`,
+		`A = ( "a" .`,
+		`A = | "a" .`,
+		`A = "a" . A = "b" .`,
+		`= "a" .`,
+		`A = "a" … .`,
+		`A = "z" … "a" .`,
+		`A = "ab" … "z" .`,
+	}
+	for _, src := range errorCases {
+		t.Run("error "+src, func(t *testing.T) {
+			if _, err := ParseGrammar(src); err == nil {
+				t.Errorf("ParseGrammar(%q) expected an error", src)
+			}
+		})
+	}
+}
+
+func TestGrammarMatch(t *testing.T) {
+	g := mustParseExampleGrammar(t)
+	tests := []struct {
+		rule  string
+		input string
+		want  bool
+	}{
+		{"Boolean", "true", true},
+		{"Boolean", "maybe", false},
+		{"SignedNumber", "+42", true},
+		{"SignedNumber", "99", true},
+		{"SignedNumber", "+", false},
+		{"Digits", "", true},
+		{"Digits", "12a45", false},
+		{"Identifier", "_private", true},
+		{"Identifier", "var123", true},
+		{"Identifier", "123var", false},
+		{"Identifier", "my-var", false},
+		{"IntLit", "0", true},
+		{"IntLit", "0xDEADBEEF", true},
+		{"IntLit", "0b1010", true},
+		{"IntLit", "00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.input, func(t *testing.T) {
+			got, err := g.Match(tt.rule, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%q, %q) = %v want %v", tt.rule, tt.input, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("undefined rule", func(t *testing.T) {
+		if _, err := g.Match("Nope", "x"); err == nil {
+			t.Errorf("expected an error for an undefined rule")
+		}
+	})
+	t.Run("undefined reference", func(t *testing.T) {
+		g, _ := ParseGrammar(`A = B .`)
+		if _, err := g.Match("A", "x"); err == nil {
+			t.Errorf("expected an error for an undefined reference")
+		}
+	})
+}
+
+// matchUncompiled is Match without the compiled cache, for comparison.
+func matchUncompiled(g *Grammar, rule, input string) (bool, error) {
+	ends, err := g.matchExpr(g.Rule(rule).Expr, input, 0)
+	return slices.Contains(ends, len(input)), err
+}
+
+func TestCompiledMatchAgrees(t *testing.T) {
+	g, err := ParseGrammar(exampleGrammar + `
+Nested = "(" { Nested } ")" .
+Words  = Word { " " Word } .
+Word   = unicode_letter { unicode_letter } .
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inputs := []string{
+		"", "0", "00", "42", "+42", "-", "0xFF", "0xG", "0b101", "0o17", "true", "false",
+		"_x9", "9x", "héllo wörld", "a  b", "()", "(()())", "(()", "12a45",
+	}
+
+	for _, prod := range g.Productions {
+		if err := g.CompileRule(prod.Name); err != nil {
+			t.Fatalf("CompileRule(%s): %v", prod.Name, err)
+		}
+		for _, input := range inputs {
+			want, wantErr := matchUncompiled(g, prod.Name, input)
+			got, err := g.Match(prod.Name, input)
+			if got != want || (err == nil) != (wantErr == nil) {
+				t.Errorf("Match(%s, %q) = %v, %v; uncompiled %v, %v", prod.Name, input, got, err, want, wantErr)
+			}
+		}
+	}
+
+	t.Run("undefined rule", func(t *testing.T) {
+		if err := g.CompileRule("Nope"); err == nil {
+			t.Errorf("expected an error for an undefined rule")
+		}
+	})
+}
+
+func BenchmarkGrammarMatch(b *testing.B) {
+	g, err := ParseGrammar(exampleGrammar)
+	if err != nil {
+		b.Fatal(err)
+	}
+	inputs := []string{"0", "12345", "0xDEADBEEF", "0b1010", "0o755", "00", "0xG", "987654321987654321"}
+
+	b.Run("compiled", func(b *testing.B) {
+		if err := g.CompileRule("IntLit"); err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			for _, input := range inputs {
+				g.Match("IntLit", input)
+			}
+		}
+	})
+	b.Run("uncompiled", func(b *testing.B) {
+		for b.Loop() {
+			for _, input := range inputs {
+				matchUncompiled(g, "IntLit", input)
+			}
+		}
+	})
+}
+
+func TestDescribeRule(t *testing.T) {
+	g := mustParseExampleGrammar(t)
+
+	t.Run("Boolean", func(t *testing.T) {
+		got, err := DescribeRule(g, "Boolean")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "Boolean\n" +
+			"`-- alternation\n" +
+			"    |-- \"true\"\n" +
+			"    `-- \"false\"\n"
+		if got != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+	t.Run("SignedNumber", func(t *testing.T) {
+		got, err := DescribeRule(g, "SignedNumber")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "SignedNumber\n" +
+			"`-- sequence\n" +
+			"    |-- option\n" +
+			"    |   `-- Sign\n" +
+			"    `-- Number\n"
+		if got != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+	t.Run("undefined rule", func(t *testing.T) {
+		if _, err := DescribeRule(g, "Nope"); err == nil {
+			t.Errorf("expected an error for an undefined rule")
+		}
+	})
+}
+
+func TestParseEBNFTerminal(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantValue string
+		wantRest  string
+	}{
+		{`"abc" . rest`, "abc", " . rest"},
+		{`"say \"hi\"" x`, `say "hi"`, " x"},
+		{`"a\\b"`, `a\b`, ""},
+		{`"\n\t"`, "\n\t", ""},
+		{`"…"`, "…", ""},
+		{`"\u00e9\x41"`, "éA", ""},
+		{`""`, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value, rest, err := parseEBNFTerminal(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value != tt.wantValue || rest != tt.wantRest {
+				t.Errorf("got %q, %q want %q, %q", value, rest, tt.wantValue, tt.wantRest)
+			}
+		})
+	}
+
+	for _, input := range []string{`"oops`, `"ends in escape\"`, `"bad \q escape"`, `"\'"`, "abc", `"line` + "\n" + `break"`} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, _, err := parseEBNFTerminal(input); err == nil {
+				t.Errorf("parseEBNFTerminal(%q) expected an error", input)
+			}
+		})
+	}
+
+	t.Run("escaped quote inside a grammar", func(t *testing.T) {
+		g, err := ParseGrammar(`Quote = "\"" .`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok, _ := g.Match("Quote", `"`); !ok {
+			t.Errorf("expected the escaped quote terminal to match")
+		}
+	})
+}
+
+func TestParseEBNFRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		lo, hi   rune
+		wantRest string
+	}{
+		{`"0" … "9" .`, '0', '9', " ."},
+		{`"a"..."z"`, 'a', 'z', ""},
+		{`"A" ... "F" | "a"`, 'A', 'F', ` | "a"`},
+		{`"x" … "x"`, 'x', 'x', ""},
+		{`"\u00e0" … "\u00ff"`, '\u00e0', '\u00ff', ""},
+		{`"α" … "ω"`, 'α', 'ω', ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lo, hi, rest, err := parseEBNFRange(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lo != tt.lo || hi != tt.hi || rest != tt.wantRest {
+				t.Errorf("got %q, %q, %q want %q, %q, %q", lo, hi, rest, tt.lo, tt.hi, tt.wantRest)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		`"9" … "0"`,  // reversed
+		`"ab" … "z"`, // multi-rune terminal
+		`"a" … "yz"`, // multi-rune terminal
+		`"" … "z"`,   // empty terminal
+		`"\xff" … "\xff"`,
+		`"a" "z"`, // no ellipsis
+		`"a" …`,   // no upper bound
+		`a … "z"`, // not a terminal
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, _, _, err := parseEBNFRange(input); err == nil {
+				t.Errorf("parseEBNFRange(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestGrammarString(t *testing.T) {
+	t.Run("normalizes spacing", func(t *testing.T) {
+		g, err := ParseGrammar("Number=Digit{Digit}.\nDigit  =  \"0\"...\"9\" .\nEmpty = .")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := g.String()
+		want := "Number = Digit { Digit } .\nDigit = \"0\" … \"9\" .\nEmpty = .\n"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+
+	grammars := map[string]string{
+		"Identifier": `
+Identifier = letter { letter | unicode_digit | "_" } .
+letter     = "a" … "z" | "A" … "Z" | "_" .`,
+		"IntLit": `
+IntLit       = DecimalLit | BinaryLit | OctalLit | HexLit .
+DecimalLit   = ( "1" … "9" ) { DecimalDigit } | "0" .
+BinaryLit    = "0" ( "b" | "B" ) BinaryDigit { BinaryDigit } .
+OctalLit     = "0" ( "o" | "O" ) [ "_" ] OctalDigit { OctalDigit } .
+HexLit       = "0" ( "x" | "X" ) HexDigit { HexDigit } .
+DecimalDigit = "0" … "9" .
+BinaryDigit  = "0" | "1" .
+OctalDigit   = "0" … "7" .
+HexDigit     = "0" … "9" | "A" … "F" | "a" … "f" .`,
+		"escapes": `Quote = "\"" | "\\" | "\t" | "é" .`,
+		"example": exampleGrammar,
+	}
+	for name, src := range grammars {
+		t.Run("round trip "+name, func(t *testing.T) {
+			g, err := ParseGrammar(src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			again, err := ParseGrammar(g.String())
+			if err != nil {
+				t.Fatalf("re-parsing %q: %v", g.String(), err)
+			}
+			if !reflect.DeepEqual(again, g) {
+				t.Errorf("round trip changed the grammar:\n%s\nbecame\n%s", g, again)
+			}
+			if again.String() != g.String() {
+				t.Errorf("String is not stable: %q then %q", g.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestGrammarValidate(t *testing.T) {
+	valid := []string{
+		exampleGrammar,
+		`Expr = Term { "+" Term } . Term = "x" | "(" Expr ")" .`, // recursion after a token is fine
+		`List = "[" [ Items ] "]" . Items = Item { "," Item } . Item = "x" | List .`,
+		`Name = unicode_letter { unicode_letter | unicode_digit } .`,
+		`Empty = .`,
+	}
+	for _, src := range valid {
+		g, err := ParseGrammar(src)
+		if err != nil {
+			t.Fatalf("ParseGrammar(%q): %v", src, err)
+		}
+		if err := g.Validate(); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", src, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{"undefined reference", `Number = Digit { Digit } .`,
+			"production Number: undefined production Digit"},
+		{"undefined inside option", `A = "a" [ B ] . C = "c" .`,
+			"production A: undefined production B"},
+		{"direct left recursion", `Expr = Expr "+" Term | Term . Term = "x" .`,
+			"left recursion: Expr -> Expr"},
+		{"indirect left recursion", `A = B "x" . B = C | "b" . C = A "c" .`,
+			"left recursion: A -> B -> C -> A"},
+		{"through a nullable prefix", `A = [ "y" ] A "x" | "z" .`,
+			"left recursion: A -> A"},
+		{"through a nullable rule", `A = Opt A "x" | "z" . Opt = { "o" } .`,
+			"left recursion: A -> A"},
+		{"inside a repetition", `List = { List "," } "x" .`,
+			"left recursion: List -> List"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseGrammar(tt.src)
+			if err != nil {
+				t.Fatalf("ParseGrammar: %v", err)
+			}
+			err = g.Validate()
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %q", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Validate() = %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMatchLeftRecursion(t *testing.T) {
+	g, err := ParseGrammar(`
+Expr   = Expr ( "+" | "-" ) Term | Term .
+Term   = Term "*" Factor | Factor .
+Factor = Number | "(" Expr ")" .
+Number = Digit { Digit } .
+Digit  = "0" … "9" .
+
+A = B "a" | "x" .
+B = A "b" .
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		rule, input string
+		want        bool
+	}{
+		{"Expr", "1", true},
+		{"Expr", "1+2+3", true},
+		{"Expr", "10-2*3+4", true},
+		{"Expr", "2*(3+4)*5", true},
+		{"Expr", "1++2", false},
+		{"Expr", "1+", false},
+		{"Expr", "+1", false},
+		{"Expr", "(1+2", false},
+		{"Expr", "", false},
+		{"A", "x", true}, // indirect: A -> B -> A
+		{"A", "xba", true},
+		{"A", "xbaba", true},
+		{"A", "xb", false},
+		{"B", "xbab", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.input, func(t *testing.T) {
+			got, err := g.Match(tt.rule, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Match(%s, %q) = %v want %v", tt.rule, tt.input, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("non-recursive rules keep the plain matcher", func(t *testing.T) {
+		got := g.leftRecursiveRules()
+		want := map[string]bool{"Expr": true, "Term": true, "A": true, "B": true}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("leftRecursiveRules() = %v want %v", got, want)
+		}
+	})
+}
+
+func TestFirstSet(t *testing.T) {
+	g, err := ParseGrammar(exampleGrammar + `
+Statement = "if" Cond | "for" Cond | Assign .
+Assign    = [ "var" ] Identifier "=" .
+Cond      = .
+Program   = { Statement } "." .
+Word      = unicode_letter { unicode_letter } .
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	letters := func(extra ...string) map[string]bool {
+		set := map[string]bool{}
+		for c := 'a'; c <= 'z'; c++ {
+			set[string(c)] = true
+			set[strings.ToUpper(string(c))] = true
+		}
+		for _, s := range extra {
+			set[s] = true
+		}
+		return set
+	}
+	digits := func(extra ...string) map[string]bool {
+		set := map[string]bool{}
+		for c := '0'; c <= '9'; c++ {
+			set[string(c)] = true
+		}
+		for _, s := range extra {
+			set[s] = true
+		}
+		return set
+	}
+
+	tests := []struct {
+		rule string
+		want map[string]bool
+	}{
+		{"Boolean", map[string]bool{"true": true, "false": true}},
+		{"Identifier", letters("_")},
+		{"SignedNumber", digits("+", "-")}, // the optional Sign is skippable
+		{"Digits", digits("")},             // can match nothing
+		{"IntLit", digits()},
+		{"Statement", letters("_", "if", "for", "var")},
+		{"Program", letters("_", "if", "for", "var", ".")},
+		{"Cond", map[string]bool{"": true}},
+		{"Word", map[string]bool{"unicode_letter": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := FirstSet(g, tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FirstSet(%s) = %v want %v", tt.rule, slices.Sorted(maps.Keys(got)), slices.Sorted(maps.Keys(tt.want)))
+			}
+		})
+	}
+
+	t.Run("left recursion", func(t *testing.T) {
+		g, _ := ParseGrammar(`Expr = Expr "+" Term | Term . Term = "x" | "(" Expr ")" .`)
+		got, err := FirstSet(g, "Expr")
+		if want := map[string]bool{"x": true, "(": true}; err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("FirstSet(Expr) = %v, %v want %v", got, err, want)
+		}
+	})
+
+	for _, src := range []string{`A = B .`, `A = "a" .`} {
+		t.Run("error "+src, func(t *testing.T) {
+			g, _ := ParseGrammar(src)
+			if _, err := FirstSet(g, "B"); err == nil {
+				t.Errorf("FirstSet(B) expected an error")
+			}
+		})
+	}
+}
+
+func TestCanonicalizeGrammar(t *testing.T) {
+	a := `
+Number = Digit { Digit } .
+Digit  = "0" … "9" .
+Sign   = "+" | "-" .
+`
+	b := "Sign=\"+\"|`-`.\n\n\tDigit = ( \"0\"...\"9\" ) .  Number =\n  (Digit) {(Digit)} ."
+
+	canonA, err := CanonicalizeGrammar(a)
+	if err != nil {
+		t.Fatalf("CanonicalizeGrammar(a): %v", err)
+	}
+	canonB, err := CanonicalizeGrammar(b)
+	if err != nil {
+		t.Fatalf("CanonicalizeGrammar(b): %v", err)
+	}
+	if canonA != canonB {
+		t.Errorf("equivalent grammars differ:\n%s\nvs\n%s", canonA, canonB)
+	}
+
+	want := `Digit = "0" … "9" .
+Number = Digit { Digit } .
+Sign = "+" | "-" .
+`
+	if canonA != want {
+		t.Errorf("got\n%s\nwant\n%s", canonA, want)
+	}
+
+	t.Run("idempotent", func(t *testing.T) {
+		again, err := CanonicalizeGrammar(canonA)
+		if err != nil || again != canonA {
+			t.Errorf("second pass = %q, %v want %q", again, err, canonA)
+		}
+	})
+
+	t.Run("meaningful groups stay", func(t *testing.T) {
+		got, err := CanonicalizeGrammar(`A = ( "a" | "b" ) ( "c" "d" ) ( [ "e" ] ) .`)
+		if want := "A = ( \"a\" | \"b\" ) ( \"c\" \"d\" ) [ \"e\" ] .\n"; err != nil || got != want {
+			t.Errorf("got %q, %v want %q", got, err, want)
+		}
+	})
+
+	t.Run("different grammars differ", func(t *testing.T) {
+		x, _ := CanonicalizeGrammar(`A = "a" | "b" .`)
+		y, _ := CanonicalizeGrammar(`A = "b" | "a" .`)
+		if x == y {
+			t.Errorf("alternatives in a different order canonicalized equal: %q", x)
+		}
+	})
+
+	if _, err := CanonicalizeGrammar(`A = "a"`); err == nil {
+		t.Errorf("expected an error for an unterminated production")
+	}
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// LEXICAL HELPERS - Shared scanning building blocks
+// ============================================================================
+//
+// The parsers in this package work on plain strings. These helpers take care
+// of the fiddly parts (nesting, literals) so each parser can stay close to the
+// EBNF production it implements.
+
+// matchingBracket returns the index of the bracket that closes the one at
+// s[open], or -1 if it is never closed. Nested (), [], and {} pairs are
+// skipped, as are string, raw string, and rune literals, so brackets inside
+// "x)" or ']' do not count.
+func matchingBracket(s string, open int) int {
+	var stack []byte
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', '[', '{':
+			stack = append(stack, c)
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != openerFor(c) {
+				return -1 // mismatched nesting
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i
+			}
+		case '"', '\'', '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				return -1
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// openerFor maps a closing bracket to its opening bracket.
+func openerFor(c byte) byte {
+	switch c {
+	case ')':
+		return '('
+	case ']':
+		return '['
+	}
+	return '{'
+}
+
+// skipQuoted returns the index of the quote that terminates the literal
+// starting at s[start], or -1 if it is unterminated. Backslash escapes are
+// honoured in interpreted strings and rune literals but not in raw strings.
+func skipQuoted(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote != '`':
+			i++ // skip the escaped character
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// maxNestingDepth bounds how deeply brackets may nest in splitDelimitedList
+// (and so in parseFunctionCall's arguments), keeping pathological inputs from
+// growing the bracket stack without limit.
+var maxNestingDepth = 256
+
+// splitDelimitedList splits s on sep where it appears at the top level, i.e.
+// not inside brackets or string/rune literals, and trims each element. It is
+// the List = Element { sep Element } half of rules like ArgumentList and
+// ExpressionList. Blank input yields an empty list; unbalanced brackets and
+// unterminated literals are errors, as is nesting deeper than
+// maxNestingDepth.
+func splitDelimitedList(s string, sep rune) ([]string, error) {
+	return splitDelimited(s, sep, false)
+}
+
+// splitDelimited is splitDelimitedList with a choice about a trailing
+// separator. With allowTrailing, "1, 2," gives [1 2] instead of [1 2 ""], as
+// Go allows in multi-line argument, parameter, and element lists. Only one
+// trailing empty element is dropped, so "1,,2" and "1,," still have empty
+// elements for the caller to reject.
+func splitDelimited(s string, sep rune, allowTrailing bool) ([]string, error) {
+	parts, _, err := splitDelimitedAt(s, sep, allowTrailing)
+	return parts, err
+}
+
+// splitDelimitedAt is splitDelimited that also returns the byte offset in
+// s where each part starts, after its leading whitespace, for callers that
+// report errors by position. On error, the last offset is that of the part
+// being read when the error was found.
+func splitDelimitedAt(s string, sep rune, allowTrailing bool) (parts []string, starts []int, err error) {
+	if strings.TrimSpace(s) == "" {
+		return []string{}, []int{}, nil
+	}
+
+	// partStart skips the whitespace a part begins with
+	partStart := func(from, to int) int {
+		return to - len(strings.TrimLeftFunc(s[from:to], unicode.IsSpace))
+	}
+	failAt := func(start int, format string, args ...any) ([]string, []int, error) {
+		return nil, append(starts, partStart(start, len(s))), fmt.Errorf(format, args...)
+	}
+
+	sepStr := string(sep)
+	parts = []string{}
+	var stack []byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '(' || c == '[' || c == '{':
+			if len(stack) == maxNestingDepth {
+				return failAt(start, "nesting too deep at offset %d (limit %d)", i, maxNestingDepth)
+			}
+			stack = append(stack, c)
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) == 0 || stack[len(stack)-1] != openerFor(c) {
+				return failAt(start, "unbalanced %q at offset %d", c, i)
+			}
+			stack = stack[:len(stack)-1]
+		case c == '"' || c == '\'' || c == '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				return failAt(start, "unterminated literal at offset %d", i)
+			}
+			i = end
+		case len(stack) == 0 && strings.HasPrefix(s[i:], sepStr):
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			starts = append(starts, partStart(start, i))
+			start = i + len(sepStr)
+			i = start - 1
+		}
+	}
+	if len(stack) > 0 {
+		return failAt(start, "unclosed %q", stack[len(stack)-1])
+	}
+
+	last := strings.TrimSpace(s[start:])
+	if allowTrailing && last == "" && len(parts) > 0 {
+		return parts, starts, nil
+	}
+	return append(parts, last), append(starts, partStart(start, len(s))), nil
+}
+
+// indexTopLevel returns the byte index of the first sub in s that is outside
+// brackets and string/rune literals, or -1.
+func indexTopLevel(s, sub string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\'' || c == '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				return -1
+			}
+			i = end
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sub):
+			return i
+		}
+	}
+	return -1
+}
+
+// Example usage:
+// matchingBracket("f(a, (b))", 1)     // 8
+// matchingBracket("m[\"]\"]", 1)      // 5 (the quoted "]" is skipped)
+// matchingBracket("f(a", 1)           // -1
+// indexTopLevel(`f("<-") <- x`, "<-")  // 8
+// splitDelimitedList(`a, f(x, y), "z,z"`, ',')  // ["a", "f(x, y)", "\"z,z\""]
+// splitDelimited("1, 2,", ',', true)            // ["1", "2"]
+
+// ============================================================================
+// WHITESPACE
+// ============================================================================
+// EBNF: whitespace = " " | "\t" | "\r" | newline .
+//
+// These are the only characters Go treats as white space between tokens;
+// unlike strings.TrimSpace, Unicode spaces such as U+00A0 do not count.
+
+func isWhitespace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// skipWhitespace drops the leading run of whitespace from s.
+func skipWhitespace(s string) string {
+	return strings.TrimLeftFunc(s, isWhitespace)
+}
+
+// Example usage:
+// isWhitespace('\t')            // true
+// skipWhitespace(" \t\n x y")   // "x y"
+
+// ============================================================================
+// SCANNER - Rune-at-a-time reading with a position
+// ============================================================================
+// Indexing s[0] or s[1:] directly needs a length check every time and splits
+// multibyte runes. A Scanner does both safely and remembers where it is.
+
+// eof is returned by Scanner.Peek and Scanner.Next at the end of input.
+const eof rune = -1
+
+type Scanner struct {
+	input string
+	off   int // byte offset of the next rune
+	pos   int // rune index of the next rune
+}
+
+func NewScanner(input string) *Scanner {
+	return &Scanner{input: input}
+}
+
+// Peek returns the next rune without consuming it, or eof.
+func (sc *Scanner) Peek() rune {
+	if sc.Eof() {
+		return eof
+	}
+	c, _ := utf8.DecodeRuneInString(sc.input[sc.off:])
+	return c
+}
+
+// Next consumes and returns the next rune, or eof without advancing.
+func (sc *Scanner) Next() rune {
+	if sc.Eof() {
+		return eof
+	}
+	c, width := utf8.DecodeRuneInString(sc.input[sc.off:])
+	sc.off += width
+	sc.pos++
+	return c
+}
+
+// Eof reports whether all input has been consumed.
+func (sc *Scanner) Eof() bool {
+	return sc.off >= len(sc.input)
+}
+
+// AcceptWhile consumes runes while pred holds and returns them.
+func (sc *Scanner) AcceptWhile(pred func(rune) bool) string {
+	start := sc.off
+	for !sc.Eof() && pred(sc.Peek()) {
+		sc.Next()
+	}
+	return sc.input[start:sc.off]
+}
+
+// Pos returns the rune index of the next rune, for error reporting.
+func (sc *Scanner) Pos() int {
+	return sc.pos
+}
+
+// Rest returns the unconsumed input.
+func (sc *Scanner) Rest() string {
+	return sc.input[sc.off:]
+}
+
+// Example usage:
+// sc := NewScanner("héllo 42")
+// sc.AcceptWhile(unicode.IsLetter)  // "héllo"
+// sc.Pos()                          // 5
+// sc.Next()                         // ' '
+
+// ============================================================================
+// KEYWORDS - Reserved words that may not be used as identifiers
+// ============================================================================
+// See https://go.dev/ref/spec#Keywords
+
+var keywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+func isKeyword(s string) bool {
+	return keywords[s]
+}
+
+// Example usage:
+// isKeyword("func")  // true
+// isKeyword("int")   // false (predeclared type, not a keyword)
+
+// ============================================================================
+// COMMENTS
+// ============================================================================
+// See https://go.dev/ref/spec#Comments. A "//" inside a string or rune
+// literal is part of the literal, not the start of a comment.
+
+// StripLineComment removes a trailing "//" comment from a line and returns
+// the code before it, including any whitespace.
+func StripLineComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'', '`':
+			end := skipQuoted(s, i)
+			if end == -1 {
+				return s // unterminated literal: nothing after it is a comment
+			}
+			i = end
+		case '/':
+			if strings.HasPrefix(s[i:], "//") {
+				return s[:i]
+			}
+		}
+	}
+	return s
+}
+
+// StripBlockComments removes every /* ... */ comment from src. As in the
+// spec, a comment with no newline becomes a single space and one spanning
+// lines is replaced by its newlines, so tokens stay apart and line numbers
+// are preserved. "//" comments are left alone, including any "/*" inside
+// them. An unterminated block comment runs to the end of src.
+func StripBlockComments(src string) string {
+	var b strings.Builder
+	for i := 0; i < len(src); i++ {
+		switch {
+		case src[i] == '"' || src[i] == '\'' || src[i] == '`':
+			end := skipQuoted(src, i)
+			if end == -1 {
+				b.WriteString(src[i:])
+				return b.String()
+			}
+			b.WriteString(src[i : end+1])
+			i = end
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				b.WriteString(src[i:])
+				return b.String()
+			}
+			b.WriteString(src[i : i+end])
+			i += end - 1
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			comment := src[i:]
+			if end != -1 {
+				comment = src[i : i+2+end+2]
+			}
+			if newlines := strings.Count(comment, "\n"); newlines > 0 {
+				b.WriteString(strings.Repeat("\n", newlines))
+			} else {
+				b.WriteByte(' ')
+			}
+			i += len(comment) - 1
+		default:
+			b.WriteByte(src[i])
+		}
+	}
+	return b.String()
+}
+
+// Example usage:
+// StripLineComment("f(1) // call it")                // "f(1) "
+// StripLineComment(`fmt.Println("// not a comment")`) // unchanged
+// StripBlockComments("x /* a */ = 1")                  // "x   = 1"
+
+// suggestKeyword returns the keyword token is most likely a typo of: one
+// whose edit distance from token is exactly 1. Edits are insertions,
+// deletions, substitutions, and swaps of adjacent characters, so "fro" and
+// "retrun" both count as one edit away.
+func suggestKeyword(token string) (string, bool) {
+	if token == "" || isKeyword(token) {
+		return "", false
+	}
+
+	candidates := make([]string, 0, len(keywords))
+	for kw := range keywords {
+		candidates = append(candidates, kw)
+	}
+	sort.Strings(candidates) // deterministic choice between ties
+
+	for _, kw := range candidates {
+		if editDistance(token, kw) == 1 {
+			return kw, true
+		}
+	}
+	return "", false
+}
+
+// editDistance is the Levenshtein distance between a and b, extended so that
+// swapping two adjacent runes costs 1 (optimal string alignment distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// d[i][j] is the distance between ra[:i] and rb[:j]
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// Example usage:
+// suggestKeyword("fro")     // "for", true
+// suggestKeyword("retrun")  // "return", true
+// suggestKeyword("banana")  // "", false
+
+// ============================================================================
+// ESCAPE SEQUENCES - Backslash escapes in rune and string literals
+// ============================================================================
+// EBNF: escaped_char     = `\` ( "a" | "b" | "f" | "n" | "r" | "t" | "v" | `\` | "'" | `"` ) .
+//       octal_byte_value = `\` octal_digit octal_digit octal_digit .
+//       hex_byte_value   = `\` "x" hex_digit hex_digit .
+//       little_u_value   = `\` "u" hex_digit hex_digit hex_digit hex_digit .
+//       big_u_value      = `\` "U" hex_digit hex_digit hex_digit hex_digit
+//                              hex_digit hex_digit hex_digit hex_digit .
+//
+// Both \' and \" are accepted; which one is legal depends on the quote of
+// the surrounding literal, so that check is left to the caller. Octal and
+// \x escapes denote a single byte, returned here as a rune below 256.
+
+var simpleEscapes = map[byte]rune{
+	'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v',
+	'\\': '\\', '\'': '\'', '"': '"',
+}
+
+// decodeEscape decodes the escape sequence at the start of s, which must
+// begin with a backslash, and reports how many bytes of s it used.
+func decodeEscape(s string) (r rune, width int, err error) {
+	if !strings.HasPrefix(s, `\`) {
+		return 0, 0, fmt.Errorf("escape must start with a backslash")
+	}
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf(`incomplete escape \ at end of input`)
+	}
+
+	c := s[1]
+	if r, ok := simpleEscapes[c]; ok {
+		return r, 2, nil
+	}
+
+	var digits, base int
+	switch {
+	case isOctalDigit(rune(c)):
+		digits, base = 3, 8
+	case c == 'x':
+		digits, base = 2, 16
+	case c == 'u':
+		digits, base = 4, 16
+	case c == 'U':
+		digits, base = 8, 16
+	default:
+		return 0, 0, fmt.Errorf(`unknown escape \%c`, c)
+	}
+
+	start := 2
+	if base == 8 {
+		start = 1 // the first digit follows the backslash directly
+	}
+	if len(s) < start+digits {
+		return 0, 0, fmt.Errorf("incomplete escape %s: want %d digits", s, digits)
+	}
+	for i := start; i < start+digits; i++ {
+		d := rune(s[i])
+		if (base == 8 && !isOctalDigit(d)) || (base == 16 && !isHexDigit(d)) {
+			return 0, 0, fmt.Errorf("invalid digit %q in escape %s", d, s[:start+digits])
+		}
+		r = r*rune(base) + rune(strings.IndexByte("0123456789abcdef", lowerHex(s[i])))
+	}
+
+	width = start + digits
+	switch {
+	case base == 8 && r > 255:
+		return 0, 0, fmt.Errorf("octal escape %s is more than 255", s[:width])
+	case (c == 'u' || c == 'U') && !utf8.ValidRune(r):
+		return 0, 0, fmt.Errorf("escape %s is not a valid Unicode code point", s[:width])
+	}
+	return r, width, nil
+}
+
+func lowerHex(c byte) byte {
+	if 'A' <= c && c <= 'F' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// Example usage:
+// decodeEscape(`\n rest`)  // '\n', 2, nil
+// decodeEscape(`\x41`)     // 'A', 4, nil
+// decodeEscape(`\u00e9`)   // 'é', 6, nil
+// decodeEscape(`\q`)       // error: unknown escape \q
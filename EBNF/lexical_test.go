@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMatchingBracket(t *testing.T) {
+	tests := []struct {
+		input string
+		open  int
+		want  int
+	}{
+		{"f(a, (b))", 1, 8},
+		{"[]int", 0, 1},
+		{"map[[2]int]bool", 3, 10},
+		{`m["]"]`, 1, 5},
+		{"f(')')", 1, 5},
+		{"f(`)`)", 1, 5},
+		{`f("\")")`, 1, 7},
+		{"f(a", 1, -1},
+		{"f(a]", 1, -1},
+		{`f(")`, 1, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := matchingBracket(tt.input, tt.open); got != tt.want {
+				t.Errorf("matchingBracket(%q, %d) = %d want %d", tt.input, tt.open, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitDelimitedList(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"a", []string{"a"}},
+		{"a, b ,c", []string{"a", "b", "c"}},
+		{`a, f(x, y), "z,z"`, []string{"a", "f(x, y)", `"z,z"`}},
+		{"m[i, j], []int{1, 2}, ','", []string{"m[i, j]", "[]int{1, 2}", "','"}},
+		{"a,,b", []string{"a", "", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := splitDelimitedList(tt.input, ',')
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"f(a, b", "a)", "f(a]", `"a, b`} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := splitDelimitedList(input, ','); err == nil {
+				t.Errorf("splitDelimitedList(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestSplitDelimitedTrailing(t *testing.T) {
+	tests := []struct {
+		input         string
+		allowTrailing bool
+		want          []string
+	}{
+		{"1, 2", true, []string{"1", "2"}},
+		{"1, 2,", true, []string{"1", "2"}},
+		{"1, 2,\n", true, []string{"1", "2"}},
+		{"1, 2,", false, []string{"1", "2", ""}},
+		{"1,,2", true, []string{"1", "", "2"}},
+		{"1,,", true, []string{"1", ""}},
+		{",", true, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q %v", tt.input, tt.allowTrailing), func(t *testing.T) {
+			got, err := splitDelimited(tt.input, ',', tt.allowTrailing)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexTopLevel(t *testing.T) {
+	tests := []struct {
+		s, sub string
+		want   int
+	}{
+		{`f("<-") <- x`, "<-", 8},
+		{"ch <- v", "<-", 3},
+		{"f(<-ch)", "<-", -1},
+		{"a := b", ":=", 2},
+		{`"a := b"`, ":=", -1},
+		{"", "x", -1},
+	}
+
+	for _, tt := range tests {
+		if got := indexTopLevel(tt.s, tt.sub); got != tt.want {
+			t.Errorf("indexTopLevel(%q, %q) = %d want %d", tt.s, tt.sub, got, tt.want)
+		}
+	}
+}
+
+func TestScanner(t *testing.T) {
+	t.Run("Peek does not advance", func(t *testing.T) {
+		sc := NewScanner("ab")
+		if sc.Peek() != 'a' || sc.Peek() != 'a' || sc.Pos() != 0 {
+			t.Errorf("Peek advanced the scanner")
+		}
+	})
+	t.Run("Next walks Unicode runes", func(t *testing.T) {
+		sc := NewScanner("é世x")
+		for _, want := range []rune{'é', '世', 'x'} {
+			if got := sc.Next(); got != want {
+				t.Errorf("got %q want %q", got, want)
+			}
+		}
+		if sc.Pos() != 3 {
+			t.Errorf("Pos() = %d want 3", sc.Pos())
+		}
+	})
+	t.Run("end of input", func(t *testing.T) {
+		sc := NewScanner("")
+		if !sc.Eof() || sc.Peek() != eof || sc.Next() != eof || sc.Pos() != 0 {
+			t.Errorf("empty scanner should stay at eof")
+		}
+
+		sc = NewScanner("a")
+		sc.Next()
+		if !sc.Eof() || sc.Next() != eof || sc.Pos() != 1 {
+			t.Errorf("Next past the end should return eof without advancing")
+		}
+	})
+	t.Run("AcceptWhile", func(t *testing.T) {
+		sc := NewScanner("123日本x")
+		if got := sc.AcceptWhile(isDigit); got != "123" {
+			t.Errorf("got %q want %q", got, "123")
+		}
+		if got := sc.AcceptWhile(func(c rune) bool { return c > 127 }); got != "日本" {
+			t.Errorf("got %q want %q", got, "日本")
+		}
+		if got := sc.AcceptWhile(isDigit); got != "" {
+			t.Errorf("got %q want empty", got)
+		}
+		if sc.Pos() != 5 || sc.Rest() != "x" {
+			t.Errorf("Pos() = %d, Rest() = %q want 5, %q", sc.Pos(), sc.Rest(), "x")
+		}
+	})
+}
+
+func TestIsKeyword(t *testing.T) {
+	all := []string{
+		"break", "case", "chan", "const", "continue",
+		"default", "defer", "else", "fallthrough", "for",
+		"func", "go", "goto", "if", "import",
+		"interface", "map", "package", "range", "return",
+		"select", "struct", "switch", "type", "var",
+	}
+	if len(all) != 25 || len(keywords) != 25 {
+		t.Fatalf("expected 25 keywords, registry has %d", len(keywords))
+	}
+	for _, kw := range all {
+		if !isKeyword(kw) {
+			t.Errorf("isKeyword(%q) = false want true", kw)
+		}
+	}
+
+	for _, word := range []string{"int", "string", "true", "nil", "For", "main", ""} {
+		if isKeyword(word) {
+			t.Errorf("isKeyword(%q) = true want false", word)
+		}
+	}
+}
+
+func TestStripLineComment(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"f(1) // call it", "f(1) "},
+		{"// whole line", ""},
+		{`fmt.Println("// not a comment")`, `fmt.Println("// not a comment")`},
+		{`fmt.Println("a") // "b"`, `fmt.Println("a") `},
+		{"x := '/' // slash", "x := '/' "},
+		{"x := `//raw` + y", "x := `//raw` + y"},
+		{"a / b", "a / b"},
+		{"return x", "return x"},
+	}
+
+	for _, tt := range tests {
+		if got := StripLineComment(tt.input); got != tt.want {
+			t.Errorf("StripLineComment(%q) = %q want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStripBlockComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single-line comment", "x := 1 /* one */", "x := 1  "},
+		{"two comments on one line", "a /* 1 */ + /* 2 */ b", "a   +   b"},
+		{"comment keeps tokens apart", "a/**/b", "a b"},
+		{"multi-line comment keeps newlines", "a\n/* line 1\nline 2\n*/ b", "a\n\n\n b"},
+		{"marker inside a string", `s := "/* not a comment */"`, `s := "/* not a comment */"`},
+		{"marker inside a raw string", "s := `/*`", "s := `/*`"},
+		{"marker inside a line comment", "x // see /* here\ny", "x // see /* here\ny"},
+		{"unterminated comment", "x /* never closed", "x  "},
+		{"no comments", "return x", "return x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripBlockComments(tt.input); got != tt.want {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestKeyword(t *testing.T) {
+	tests := []struct {
+		token  string
+		want   string
+		wantOK bool
+	}{
+		{"fro", "for", true},
+		{"retrun", "return", true},
+		{"swich", "switch", true},
+		{"fucn", "func", true},
+		{"imports", "import", true},
+		{"banana", "", false},
+		{"for", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			got, ok := suggestKeyword(tt.token)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("suggestKeyword(%q) = %q, %v want %q, %v", tt.token, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"for", "for", 0},
+		{"fro", "for", 1},
+		{"fo", "for", 1},
+		{"fort", "for", 1},
+		{"far", "for", 1},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsWhitespace(t *testing.T) {
+	for _, c := range []rune{' ', '\t', '\n', '\r'} {
+		if !isWhitespace(c) {
+			t.Errorf("isWhitespace(%q) = false want true", c)
+		}
+	}
+	for _, c := range []rune{'x', '0', '\u00a0', '\v', eof} {
+		if isWhitespace(c) {
+			t.Errorf("isWhitespace(%q) = true want false", c)
+		}
+	}
+}
+
+func TestSkipWhitespace(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{" \t\r\n x y ", "x y "},
+		{"x  y", "x  y"},
+		{"   ", ""},
+		{"", ""},
+		{"\u00a0x", "\u00a0x"},
+	}
+
+	for _, tt := range tests {
+		if got := skipWhitespace(tt.input); got != tt.want {
+			t.Errorf("skipWhitespace(%q) = %q want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeEscape(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantRune  rune
+		wantWidth int
+	}{
+		{`\a`, '\a', 2},
+		{`\b`, '\b', 2},
+		{`\f`, '\f', 2},
+		{`\n`, '\n', 2},
+		{`\r`, '\r', 2},
+		{`\t`, '\t', 2},
+		{`\v`, '\v', 2},
+		{`\\`, '\\', 2},
+		{`\'`, '\'', 2},
+		{`\"`, '"', 2},
+		{`\n and more`, '\n', 2},
+		{`\101`, 'A', 4},
+		{`\377`, 0xFF, 4},
+		{`\x41`, 'A', 4},
+		{`\xfF`, 0xFF, 4},
+		{`\u00e9`, 'é', 6},
+		{`\U0001F600`, '😀', 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			r, width, err := decodeEscape(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r != tt.wantRune || width != tt.wantWidth {
+				t.Errorf("got %q, %d want %q, %d", r, width, tt.wantRune, tt.wantWidth)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		`\q`,         // unknown escape
+		`\`,          // nothing after the backslash
+		`\x`,         // incomplete \x at end of input
+		`\x4`,        // one hex digit short
+		`\xZZ`,       // not hex
+		`\12`,        // octal needs three digits
+		`\400`,       // octal above 255
+		`\u12`,       // incomplete \u
+		`\uD800`,     // surrogate half
+		`\U00110000`, // beyond the Unicode range
+		"n",          // no backslash
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, _, err := decodeEscape(input); err == nil {
+				t.Errorf("decodeEscape(%q) expected an error", input)
+			}
+		})
+	}
+}
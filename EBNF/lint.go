@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// LINT - Checking identifiers in real source
+// ============================================================================
+//
+// LintIdentifiers runs Tokenize over a snippet and reports tokens that look
+// like an identifier but aren't one. Tokenize already splits "my-var" into
+// my, -, var, so the case left to catch is a name that starts with a digit:
+// Tokenize reads "2ndPlace" as one number token, and no numeric literal
+// spells it. To stay conservative, anything strconv accepts as a number
+// ("1e9", "0x1p-2", "3i") or that is only a malformed prefix ("0x", "0b2")
+// is left alone. If Tokenize fails, the tokens before the failure are
+// still checked.
+
+type LintIssue struct {
+	Line    int
+	Column  int
+	Token   string
+	Message string
+}
+
+func LintIdentifiers(src string) []LintIssue {
+	toks, _ := Tokenize(src)
+
+	var issues []LintIssue
+	for _, tok := range toks {
+		if tok.Kind != TokenNumber || !looksLikeIdentifier(tok.Value) {
+			continue
+		}
+		if err := validateIdentifier(tok.Value); err != nil {
+			issues = append(issues, LintIssue{Line: tok.Line, Column: tok.Column, Token: tok.Value, Message: err.Error()})
+		}
+	}
+	return issues
+}
+
+// looksLikeIdentifier reports whether a number token is really a name that
+// starts with digits: it isn't a numeric literal, and what follows the
+// leading digits is an identifier other than a base prefix.
+func looksLikeIdentifier(s string) bool {
+	if isNumericLiteral(s) {
+		return false
+	}
+	if s[0] == '0' && len(s) > 1 && strings.ContainsRune("bBoOxX", rune(s[1])) {
+		return false
+	}
+	rest := strings.TrimLeftFunc(s, isDigit)
+	return rest != "" && isValidIdentifier(rest)
+}
+
+func isNumericLiteral(s string) bool {
+	if isValidIntegerLenient(s) {
+		return true
+	}
+	// floats and imaginary literals: 1e9, 0x1p-2, 2.5i (split at the "." by Tokenize)
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "_", ""), "i")
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil || errors.Is(err, strconv.ErrRange)
+}
+
+// Example usage:
+// LintIdentifiers("x := 1\n2ndPlace := x")
+// // [{Line: 2, Column: 1, Token: "2ndPlace",
+// //   Message: `identifier "2ndPlace" must start with a letter or underscore`}]
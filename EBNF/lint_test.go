@@ -0,0 +1,34 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintIdentifiers(t *testing.T) {
+	t.Run("flags a name starting with a digit", func(t *testing.T) {
+		got := LintIdentifiers("first := 1\nif 2ndPlace > first {\n\t_3rd, x9 := 3, 4\n}")
+		want := []LintIssue{{
+			Line:    2,
+			Column:  4,
+			Token:   "2ndPlace",
+			Message: `identifier "2ndPlace" must start with a letter or underscore`,
+		}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+	t.Run("numeric literals are not flagged", func(t *testing.T) {
+		src := "a := 42 + 0xFF + 0b1010 + 0o17 + 0777 + 1_000 + 1e9 + 0x1p-2 + 3i + 0x + 08"
+		if got := LintIdentifiers(src); len(got) != 0 {
+			t.Errorf("got %+v want no issues", got)
+		}
+	})
+	t.Run("tokens before a tokenizer error are checked", func(t *testing.T) {
+		got := LintIdentifiers("1st @ 2nd")
+		if len(got) != 1 || got[0].Token != "1st" {
+			t.Errorf("got %+v want one issue for 1st", got)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import "fmt"
+
+// ============================================================================
+// MUST - Panicking wrappers for known-good input
+// ============================================================================
+//
+// Like regexp.MustCompile, these are for input that is part of the program,
+// such as a grammar in a var block or a literal in a test table, where a
+// parse error is a bug and there is nothing to recover. They panic with an
+// error that wraps the parser's, so a ParseError is still reachable with
+// errors.As after recover. Never pass them user input.
+//
+// The named wrappers cover the parsers used most; Must turns any other
+// parser call into a panicking one: Must(parseMapType("map[string]int")).
+
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(fmt.Errorf("must: %w", err))
+	}
+	return v
+}
+
+func MustParseFunctionCall(s string) FunctionCall {
+	return mustParse("parseFunctionCall", s, parseFunctionCall)
+}
+
+func MustParseSignedNumber(s string) SignedNumber {
+	return mustParse("parseSignedNumber", s, parseSignedNumber)
+}
+
+func MustParseExpression(s string) Node {
+	return mustParse("parseExpression", s, parseExpression)
+}
+
+func MustParseTypeSpec(s string) TypeSpec {
+	return mustParse("parseTypeSpec", s, parseTypeSpec)
+}
+
+func MustParseGrammar(src string) *Grammar {
+	return mustParse("ParseGrammar", src, ParseGrammar)
+}
+
+// mustParse runs parse and panics with the parser's name and input on error.
+func mustParse[T any](name, s string, parse func(string) (T, error)) T {
+	v, err := parse(s)
+	if err != nil {
+		panic(fmt.Errorf("%s(%q): %w", name, s, err))
+	}
+	return v
+}
+
+// Example usage:
+// var calc = MustParseGrammar(`Expr = Expr "+" Term | Term . Term = "0" … "9" .`)
+// MustParseFunctionCall("add(1, 2)")      // {Name: "add", Args: ["1", "2"]}
+// MustParseFunctionCall("add(1, 2")       // panic: parseFunctionCall("add(1, 2"): no closing parenthesis ...
+// Must(parseMapType("map[string]int"))    // {Key: string, Value: int}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recoverError runs f and returns the error it panicked with, or nil.
+func recoverError(t *testing.T, f func()) (err error) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		var ok bool
+		if err, ok = r.(error); !ok {
+			t.Fatalf("panicked with %T (%v), want an error", r, r)
+		}
+	}()
+	f()
+	return nil
+}
+
+func TestMustParsers(t *testing.T) {
+	t.Run("valid input returns the result", func(t *testing.T) {
+		if got, want := MustParseFunctionCall("add(1, 2)"), (FunctionCall{Name: "add", Arguments: []string{"1", "2"}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("MustParseFunctionCall = %+v want %+v", got, want)
+		}
+		if got, want := MustParseSignedNumber("-15"), (SignedNumber{"-", 15}); got != want {
+			t.Errorf("MustParseSignedNumber = %+v want %+v", got, want)
+		}
+		if got := MustParseExpression("a + b"); got.Kind() != "binary" {
+			t.Errorf("MustParseExpression kind = %q want binary", got.Kind())
+		}
+		if got := MustParseTypeSpec("[]int"); got.Kind != TypeKindSlice {
+			t.Errorf("MustParseTypeSpec kind = %q want %q", got.Kind, TypeKindSlice)
+		}
+		if g := MustParseGrammar(`A = "a" .`); g.Rule("A") == nil {
+			t.Errorf("MustParseGrammar lost rule A")
+		}
+		if got := Must(parseMapType("map[string]int")); got.Key.Name != "string" {
+			t.Errorf("Must(parseMapType) key = %q want string", got.Key.Name)
+		}
+	})
+
+	panics := []struct {
+		name string
+		f    func()
+		want string // in the panic message
+	}{
+		{"MustParseFunctionCall", func() { MustParseFunctionCall("add(1, 2") }, `parseFunctionCall("add(1, 2")`},
+		{"MustParseSignedNumber", func() { MustParseSignedNumber("+") }, "no digits after sign"},
+		{"MustParseExpression", func() { MustParseExpression("a b") }, "unsupported expression"},
+		{"MustParseTypeSpec", func() { MustParseTypeSpec("1x") }, "invalid type name"},
+		{"MustParseGrammar", func() { MustParseGrammar("A = ") }, "ParseGrammar"},
+		{"Must", func() { Must(parseMapType("map[int")) }, "must: "},
+	}
+	for _, tt := range panics {
+		t.Run(tt.name+" panics", func(t *testing.T) {
+			err := recoverError(t, tt.f)
+			if err == nil {
+				t.Fatalf("expected a panic")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("panic %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("panic wraps the parse error", func(t *testing.T) {
+		err := recoverError(t, func() { MustParseFunctionCall("print") })
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Pos != 5 {
+			t.Errorf("recovered %v, want a *ParseError at position 5", err)
+		}
+		err = recoverError(t, func() { MustParseExpression("") })
+		if !errors.Is(err, errEmptyInput) {
+			t.Errorf("recovered %v, want errEmptyInput", err)
+		}
+	})
+}
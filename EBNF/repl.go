@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ============================================================================
+// REPL - Trying the parsers interactively
+// ============================================================================
+//
+// Run with "go run . repl" and type Go statements one per line:
+//
+//	> defer f.Close()
+//	defer: f.Close()
+//	> return a, b
+//	return: {Values:[a b]}
+//	> fro x := range xs
+//	error: unknown statement "fro": did you mean "for"?
+//
+// A line that fails to parse prints an error and the loop carries on;
+// only EOF (Ctrl-D) or a failure to read or write ends it.
+
+const replPrompt = "> "
+
+func RunREPL(in io.Reader, out io.Writer) error {
+	sc := bufio.NewScanner(in)
+	for {
+		if _, err := io.WriteString(out, replPrompt); err != nil {
+			return err
+		}
+		if !sc.Scan() {
+			break
+		}
+
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var err error
+		if kind, node, parseErr := ClassifyStatement(line); parseErr != nil {
+			_, err = fmt.Fprintf(out, "error: %v\n", parseErr)
+		} else if node == nil {
+			_, err = fmt.Fprintf(out, "%s\n", kind)
+		} else {
+			_, err = fmt.Fprintf(out, "%s: %+v\n", kind, node)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(out, "\n"); err != nil {
+		return err
+	}
+	return sc.Err()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunREPL(t *testing.T) {
+	input := strings.Join([]string{
+		"for i := 0; i < 3; i++ {",
+		"",
+		"defer f.Close()",
+		"fro x := range xs",
+		"var x int",
+		"x = y + 1",
+	}, "\n")
+
+	var out strings.Builder
+	if err := RunREPL(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := out.String()
+
+	for _, want := range []string{
+		"> for: for i := 0; i < 3; i++ {\n",
+		"> defer: f.Close()\n",
+		"> error: unknown statement \"fro\": did you mean \"for\"?\n",
+		"> var: {Keyword:var ",
+		"> expression: x = y + 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output is missing %q; got:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, replPrompt); n != 7 {
+		t.Errorf("got %d prompts want 7 (one per line plus EOF)", n)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestRunREPLWriteError(t *testing.T) {
+	if err := RunREPL(strings.NewReader("x\n"), failingWriter{}); err == nil {
+		t.Errorf("expected the write error to be returned")
+	}
+}
@@ -0,0 +1,723 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// STATEMENTS - Classifying Go statements
+// ============================================================================
+//
+// These follow the parseForStatement example in ebnf_go_examples.go: check
+// the leading keyword, strip it, and work out what the rest of the line is.
+// Based on: https://go.dev/ref/spec#Statements
+
+// ============================================================================
+// 1. GO AND DEFER STATEMENTS
+// ============================================================================
+// EBNF: GoStmt = "go" Expression .
+//       DeferStmt = "defer" Expression .
+//
+// The Expression must be a function or method call, so both delegate to
+// parseFunctionCall. Function literals ("go func() { ... }()") are not
+// supported.
+
+func parseGoStatement(s string) (FunctionCall, error) {
+	return parseCallStatement("go", s)
+}
+
+func parseDeferStatement(s string) (FunctionCall, error) {
+	return parseCallStatement("defer", s)
+}
+
+// parseCallStatement strips keyword and leaves the call to
+// parseFunctionCall.
+func parseCallStatement(keyword, s string) (FunctionCall, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return FunctionCall{}, errEmptyInput
+	}
+	if !isKeywordPrefix(s, keyword) {
+		return FunctionCall{}, fmt.Errorf("not a %s statement", keyword)
+	}
+
+	fc, err := parseFunctionCall(strings.TrimPrefix(s, keyword))
+	if err != nil {
+		return FunctionCall{}, fmt.Errorf("%s statement requires a function call: %w", keyword, err)
+	}
+	return fc, nil
+}
+
+// Example usage:
+// parseGoStatement("go doWork(1)")          // {Name: "doWork", Arguments: ["1"]}
+// parseDeferStatement("defer file.Close()") // {Name: "file.Close", Arguments: []}
+// parseGoStatement("go x + 1")              // error: not a call
+
+// ============================================================================
+// 2. RETURN STATEMENT
+// ============================================================================
+// EBNF: ReturnStmt = "return" [ ExpressionList ] .
+//       ExpressionList = Expression { "," Expression } .
+
+type ReturnStatement struct {
+	Values []string // empty for a bare "return"
+}
+
+func parseReturnStatement(s string) (ReturnStatement, error) {
+	input := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ReturnStatement{}, errEmptyInput
+	}
+	if !isKeywordPrefix(s, "return") {
+		return ReturnStatement{}, fmt.Errorf("not a return statement")
+	}
+
+	// an empty value ("return a,,b", "return a,") is reported where it
+	// should have been, as a rune index into input
+	listAt := len(input) - len(strings.TrimLeftFunc(input, unicode.IsSpace)) + len("return")
+	values, starts, err := splitDelimitedAt(input[listAt:], ',', false)
+	if err != nil {
+		return ReturnStatement{}, fmt.Errorf("return values: %w", err)
+	}
+	for i, value := range values {
+		if value == "" {
+			pos := utf8.RuneCountInString(input[:listAt+starts[i]])
+			return ReturnStatement{}, newParseError(pos, "missing return value %d", i+1)
+		}
+	}
+	return ReturnStatement{Values: values}, nil
+}
+
+// Example usage:
+// parseReturnStatement("return")                       // {Values: []}
+// parseReturnStatement("return a, f(x, y), \"z,z\"")   // {Values: [a f(x, y) "z,z"]}
+// parseReturnStatement("return a,,b")                  // error: missing return value 2 at position 9
+
+// ============================================================================
+// 3. LABELS
+// ============================================================================
+// EBNF: Label = identifier .
+//       LabeledStmt = Label ":" Statement .
+//       GotoStmt = "goto" Label .
+//
+// A label is an identifier, and like any identifier it cannot be a keyword.
+// parseLabeledStatement allows an empty statement after the colon, since
+// the labelled statement usually starts on the next line.
+
+func isValidLabel(s string) bool {
+	return isValidIdentifier(s) && !isKeyword(s)
+}
+
+func parseGotoStatement(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errEmptyInput
+	}
+	if !isKeywordPrefix(s, "goto") {
+		return "", fmt.Errorf("not a goto statement: %q", s)
+	}
+	label := strings.TrimSpace(s[len("goto"):])
+	if label == "" {
+		return "", fmt.Errorf("missing label after goto")
+	}
+	if !isValidLabel(label) {
+		return "", fmt.Errorf("invalid label %q", label)
+	}
+	return label, nil
+}
+
+func parseLabeledStatement(s string) (label string, rest string, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", "", errEmptyInput
+	}
+	label = leadingIdentifier(s)
+	after := strings.TrimSpace(s[len(label):])
+	if label == "" || !strings.HasPrefix(after, ":") || strings.HasPrefix(after, ":=") {
+		return "", "", fmt.Errorf("not a labeled statement: %q", s)
+	}
+	if !isValidLabel(label) {
+		return "", "", fmt.Errorf("invalid label %q", label)
+	}
+	return label, strings.TrimSpace(after[1:]), nil
+}
+
+// Example usage:
+// isValidLabel("Loop")    // true
+// isValidLabel("retry2")  // true
+// isValidLabel("for")     // false (keyword)
+// isValidLabel("123")     // false (not an identifier)
+// parseGotoStatement("goto Done")                     // "Done"
+// parseGotoStatement("goto 123")                      // error: invalid label
+// parseLabeledStatement("Loop: for i := range xs {")  // "Loop", "for i := range xs {"
+// parseLabeledStatement("x := 1")                     // error: not a labeled statement
+
+// ============================================================================
+// 4. CLASSIFYING A LINE
+// ============================================================================
+// EBNF: SimpleStmt = ExpressionStmt | ... .
+//       Statement = Declaration | SimpleStmt | GoStmt | ReturnStmt | IfStmt | SwitchStmt | ForStmt | DeferStmt | ... .
+//
+// ClassifyStatement looks at the leading keyword and hands the line to the
+// matching parser. Declarations go to parseGenDecl, or to parseImportSpec
+// and parseImportBlock for imports, and an identifier followed by a single
+// colon is a label. Anything else is an "expression", unless it starts with
+// a misspelt keyword followed by another word.
+
+func ClassifyStatement(line string) (kind string, node Node, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", nil, errEmptyInput
+	}
+
+	switch kind = leadingIdentifier(line); kind {
+	case "for":
+		node, err = parseForStatement(line)
+	case "if":
+		node, err = parseIfStatement(line)
+	case "switch":
+		node, err = parseSwitchStatement(line)
+	case "go":
+		node, err = parseGoStatement(line)
+	case "defer":
+		node, err = parseDeferStatement(line)
+	case "return":
+		node, err = parseReturnStatement(line)
+	case "goto":
+		var label string
+		if label, err = parseGotoStatement(line); err == nil {
+			node = RawExpr(label)
+		}
+	case "package":
+		var name string
+		if name, err = parsePackageClause(line); err == nil {
+			node = RawExpr(name)
+		}
+	case "var", "const", "type":
+		node, err = parseGenDecl(line)
+	case "import":
+		if strings.HasPrefix(strings.TrimSpace(line[len(kind):]), "(") {
+			var specs []ImportSpec
+			if specs, err = parseImportBlock(line); err == nil {
+				node = ImportBlock(specs)
+			}
+		} else {
+			node, err = parseImportSpec(line)
+		}
+	default:
+		if after := strings.TrimSpace(line[len(kind):]); kind != "" && !isKeyword(kind) &&
+			strings.HasPrefix(after, ":") && !strings.HasPrefix(after, ":=") {
+			var label string
+			if label, _, err = parseLabeledStatement(line); err != nil {
+				return "label", nil, err
+			}
+			return "label", RawExpr(label), nil
+		}
+		// "word word ..." is never an expression; a near-keyword first word
+		// is most likely a typo ("fro x := range xs")
+		after := line[len(kind):]
+		rest := strings.TrimLeft(after, " \t")
+		if rest != after && rest != "" && (isLetter(rune(rest[0])) || rest[0] == '_') {
+			if suggestion, ok := suggestKeyword(kind); ok {
+				return "", nil, fmt.Errorf("unknown statement %q: did you mean %q?", kind, suggestion)
+			}
+		}
+		return "expression", RawExpr(line), nil
+	}
+
+	if err != nil {
+		return kind, nil, err
+	}
+	return kind, node, nil
+}
+
+// Example usage:
+// ClassifyStatement("for i := 0; i < 3; i++ {")  // "for", ForStatement{...}, nil
+// ClassifyStatement("defer f.Close()")           // "defer", FunctionCall{...}, nil
+// ClassifyStatement("var x int")                 // "var", GenDecl{...}, nil
+// ClassifyStatement("Loop:")                     // "label", RawExpr("Loop"), nil
+// ClassifyStatement("x = y + 1")                 // "expression", RawExpr("x = y + 1"), nil
+
+// ============================================================================
+// 5. SHORT VARIABLE DECLARATION
+// ============================================================================
+// EBNF: ShortVarDecl = IdentifierList ":=" ExpressionList .
+//       IdentifierList = identifier { "," identifier } .
+
+type ShortVarDecl struct {
+	Names []string // may include the blank identifier "_"
+	Value string   // the ExpressionList, unsplit
+}
+
+func parseShortVarDecl(s string) (ShortVarDecl, error) {
+	if strings.TrimSpace(s) == "" {
+		return ShortVarDecl{}, errEmptyInput
+	}
+	// the first top-level operator that assigns must be ":=", so "x = y"
+	// and "x += 1" are rejected while comparisons like "==" are skipped
+	idx := -1
+	for i := 0; idx == -1; i++ {
+		next := indexTopLevel(s[i:], "=")
+		if next == -1 {
+			return ShortVarDecl{}, fmt.Errorf("not a short variable declaration: missing :=")
+		}
+		i += next
+		op, width := classifyAssignOp(s, i)
+		switch op {
+		case ":=":
+			idx = i + width - len(op)
+		case "==", "!=", "<=", ">=":
+			i += width - 1
+		default:
+			return ShortVarDecl{}, fmt.Errorf("not a short variable declaration: found %s instead of :=", op)
+		}
+	}
+
+	names, err := parseIdentifierList(s[:idx])
+	if err != nil {
+		return ShortVarDecl{}, err
+	}
+
+	value := strings.TrimSpace(s[idx+len(":="):])
+	if value == "" {
+		return ShortVarDecl{}, fmt.Errorf("missing value after :=")
+	}
+	return ShortVarDecl{Names: names, Value: value}, nil
+}
+
+// parseIdentifierList splits a comma-separated IdentifierList and checks each
+// name is an identifier and not a keyword. The blank identifier is allowed.
+func parseIdentifierList(s string) ([]string, error) {
+	names := strings.Split(s, ",")
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if err := validateIdentifier(name); err != nil {
+			return nil, fmt.Errorf("invalid identifier list %q: %w", strings.TrimSpace(s), err)
+		}
+		if isKeyword(name) {
+			return nil, fmt.Errorf("invalid identifier list %q: %q is a keyword", strings.TrimSpace(s), name)
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// Example usage:
+// parseShortVarDecl("x := 5")          // {Names: [x], Value: "5"}
+// parseShortVarDecl("a, b := f()")     // {Names: [a b], Value: "f()"}
+// parseShortVarDecl("_, err := f()")   // {Names: [_ err], Value: "f()"}
+// parseShortVarDecl("x = 5")           // error: missing :=
+
+// ============================================================================
+// 6. IF STATEMENT
+// ============================================================================
+// EBNF: IfStmt = "if" [ SimpleStmt ";" ] Expression Block [ "else" ( IfStmt | Block ) ] .
+//
+// The header ends at the first "{" outside brackets and literals. Go needs
+// a composite literal in the condition to be parenthesised for the same
+// reason, so "if (T{}) == x {" still works. The body itself isn't parsed;
+// if it is closed on the same line, an "else" after it is noted.
+
+type IfStatement struct {
+	Init      string // the SimpleStmt before ";", or ""
+	Condition string
+	HasElse   bool
+}
+
+func parseIfStatement(s string) (IfStatement, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return IfStatement{}, errEmptyInput
+	}
+	if !isKeywordPrefix(s, "if") {
+		return IfStatement{}, fmt.Errorf("not an if statement")
+	}
+
+	rest := s[len("if"):]
+	open := blockStart(rest)
+	if open == -1 {
+		return IfStatement{}, fmt.Errorf("missing block in if statement %q", s)
+	}
+
+	var is IfStatement
+	header := strings.TrimSpace(rest[:open])
+	if semi := indexTopLevel(header, ";"); semi != -1 {
+		is.Init = strings.TrimSpace(header[:semi])
+		header = strings.TrimSpace(header[semi+1:])
+	}
+	if header == "" {
+		return IfStatement{}, fmt.Errorf("missing condition in if statement %q", s)
+	}
+	is.Condition = header
+
+	if closeIdx := matchingBracket(rest, open); closeIdx != -1 {
+		is.HasElse = isKeywordPrefix(strings.TrimSpace(rest[closeIdx+1:]), "else")
+	}
+	return is, nil
+}
+
+// blockStart returns the index of the first "{" in s that is outside
+// parentheses, brackets, and literals, or -1.
+func blockStart(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'', '`':
+			if i = skipQuoted(s, i); i == -1 {
+				return -1
+			}
+		case '(', '[':
+			if i = matchingBracket(s, i); i == -1 {
+				return -1
+			}
+		case '{':
+			return i
+		}
+	}
+	return -1
+}
+
+// Example usage:
+// parseIfStatement("if x > 0 {")                    // {Condition: "x > 0"}
+// parseIfStatement("if v, ok := m[k]; ok {")        // {Init: "v, ok := m[k]", Condition: "ok"}
+// parseIfStatement("if err != nil { } else { }")    // {Condition: "err != nil", HasElse: true}
+// parseIfStatement("if {")                          // error: missing condition
+
+// ============================================================================
+// 7. SWITCH STATEMENT
+// ============================================================================
+// EBNF: SwitchStmt = ExprSwitchStmt | TypeSwitchStmt .
+//       ExprSwitchStmt = "switch" [ SimpleStmt ";" ] [ Expression ] "{" { ExprCaseClause } "}" .
+//       TypeSwitchStmt = "switch" [ SimpleStmt ";" ] TypeSwitchGuard "{" { TypeCaseClause } "}" .
+//       TypeSwitchGuard = [ identifier ":=" ] PrimaryExpr "." "(" "type" ")" .
+//
+// The header is split like an if statement's. A ":=" in the tag is only
+// allowed as part of a type switch guard.
+
+type SwitchStatement struct {
+	Init         string // the SimpleStmt before ";", or ""
+	Tag          string // the Expression or TypeSwitchGuard, or "" for "switch {"
+	IsTypeSwitch bool
+}
+
+func parseSwitchStatement(s string) (SwitchStatement, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return SwitchStatement{}, errEmptyInput
+	}
+	if !isKeywordPrefix(s, "switch") {
+		return SwitchStatement{}, fmt.Errorf("not a switch statement")
+	}
+
+	rest := s[len("switch"):]
+	open := blockStart(rest)
+	if open == -1 {
+		return SwitchStatement{}, fmt.Errorf("missing block in switch statement %q", s)
+	}
+
+	var ss SwitchStatement
+	header := strings.TrimSpace(rest[:open])
+	if semi := indexTopLevel(header, ";"); semi != -1 {
+		ss.Init = strings.TrimSpace(header[:semi])
+		header = strings.TrimSpace(header[semi+1:])
+	}
+	ss.Tag = header
+	ss.IsTypeSwitch = strings.HasSuffix(header, ".(type)")
+
+	if !ss.IsTypeSwitch && indexTopLevel(header, ":=") != -1 {
+		return SwitchStatement{}, fmt.Errorf("switch tag %q is a declaration, not an expression", header)
+	}
+	return ss, nil
+}
+
+// Example usage:
+// parseSwitchStatement("switch x {")                // {Tag: "x"}
+// parseSwitchStatement("switch x := f(); x {")      // {Init: "x := f()", Tag: "x"}
+// parseSwitchStatement("switch v := x.(type) {")    // {Tag: "v := x.(type)", IsTypeSwitch: true}
+// parseSwitchStatement("switch {")                  // {} (tagless, like "switch true")
+
+// ============================================================================
+// 8. ASSIGNMENT
+// ============================================================================
+// EBNF: Assignment = ExpressionList assign_op ExpressionList .
+//       assign_op = [ add_op | mul_op ] "=" .
+//       add_op = "+" | "-" | "|" | "^" .
+//       mul_op = "*" | "/" | "%" | "<<" | ">>" | "&" | "&^" .
+//
+// The operator is the first top-level "=" that isn't part of "==", "!=",
+// "<=", ">=", or ":=". A compound assignment ("x += 1") takes exactly one
+// operand on each side. "a, b = f()" is allowed since f may return two
+// values, but "a, b = 1, 2, 3" is a mismatch.
+
+type Assignment struct {
+	LHS []string
+	RHS []string
+	Op  string // "=", "+=", "<<=", ...
+}
+
+func parseAssignment(s string) (Assignment, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Assignment{}, errEmptyInput
+	}
+	start, end := assignOpIndex(s)
+	if start == -1 {
+		return Assignment{}, fmt.Errorf("not an assignment: %q", s)
+	}
+
+	a := Assignment{Op: s[start:end]}
+	var err error
+	if a.LHS, err = splitExpressionList(s[:start]); err != nil {
+		return Assignment{}, fmt.Errorf("invalid left side: %w", err)
+	}
+	if a.RHS, err = splitExpressionList(s[end:]); err != nil {
+		return Assignment{}, fmt.Errorf("invalid right side: %w", err)
+	}
+
+	switch {
+	case a.Op != "=" && (len(a.LHS) != 1 || len(a.RHS) != 1):
+		return Assignment{}, fmt.Errorf("compound assignment %s needs one operand on each side", a.Op)
+	case len(a.RHS) > 1 && len(a.RHS) != len(a.LHS):
+		return Assignment{}, fmt.Errorf("assignment mismatch: %d variables but %d values", len(a.LHS), len(a.RHS))
+	}
+	return a, nil
+}
+
+// assignOpIndex returns the byte range of the assign_op in s, or -1, -1.
+func assignOpIndex(s string) (start, end int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\'', '`':
+			if i = skipQuoted(s, i); i == -1 {
+				return -1, -1
+			}
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '=', ':':
+			if depth != 0 {
+				continue
+			}
+			op, width := classifyAssignOp(s, i)
+			switch op {
+			case "==", "!=", "<=", ">=", ":=", ":":
+				i += width - 1
+				continue
+			}
+			return i + width - len(op), i + width
+		}
+	}
+	return -1, -1
+}
+
+// classifyAssignOp reads the operator around the '=' or ':' at s[i], so
+// callers scanning for an assignment don't mistake a comparison or a short
+// variable declaration for one. op is the whole token, looking back for a
+// compound prefix like the "<<" of "<<=" and forward for the second half
+// of "==" or ":=". width counts the bytes of op from s[i] on, so i+width
+// is just past the operator and it starts at i+width-len(op). op is ""
+// when s[i] is neither '=' nor ':'.
+func classifyAssignOp(s string, i int) (op string, width int) {
+	if i < 0 || i >= len(s) {
+		return "", 0
+	}
+	next := func(c byte) bool { return i+1 < len(s) && s[i+1] == c }
+	prev := func(n int, c byte) bool { return i-n >= 0 && s[i-n] == c }
+
+	switch s[i] {
+	case ':':
+		if next('=') {
+			return ":=", 2
+		}
+		return ":", 1
+	case '=':
+	default:
+		return "", 0
+	}
+
+	switch {
+	case prev(1, '=') || prev(1, '!') || prev(1, ':'):
+		return s[i-1 : i+1], 1 // second half of "==", "!=", or ":="
+	case prev(1, '<') && prev(2, '<'), prev(1, '>') && prev(2, '>'), prev(1, '^') && prev(2, '&'):
+		return s[i-2 : i+1], 1 // "<<=", ">>=", or "&^="
+	case prev(1, '<') || prev(1, '>'):
+		return s[i-1 : i+1], 1 // "<=" or ">="
+	case next('='):
+		return "==", 2
+	case i > 0 && strings.IndexByte("+-*/%&|^", s[i-1]) != -1:
+		return s[i-1 : i+1], 1
+	}
+	return "=", 1
+}
+
+// splitExpressionList splits an ExpressionList on top-level commas and
+// rejects empty elements.
+func splitExpressionList(s string) ([]string, error) {
+	exprs, err := splitDelimitedList(s, ',')
+	if err != nil {
+		return nil, err
+	}
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("missing expression")
+	}
+	for _, e := range exprs {
+		if e == "" {
+			return nil, fmt.Errorf("empty expression in list %q", strings.TrimSpace(s))
+		}
+	}
+	return exprs, nil
+}
+
+// Example usage:
+// parseAssignment("a = 1")          // {LHS: [a], RHS: [1], Op: "="}
+// parseAssignment("a, b = b, a")    // {LHS: [a b], RHS: [b a], Op: "="}
+// parseAssignment("_, err = f()")   // {LHS: [_ err], RHS: [f()], Op: "="}
+// parseAssignment("x <<= 2")        // {LHS: [x], RHS: [2], Op: "<<="}
+// parseAssignment("a == b")         // error: not an assignment
+
+// ============================================================================
+// 9. SELECT CASES
+// ============================================================================
+// EBNF: CommClause = CommCase ":" StatementList .
+//       CommCase = "case" ( SendStmt | RecvStmt ) | "default" .
+//       RecvStmt = [ ExpressionList "=" | IdentifierList ":=" ] RecvExpr .
+//
+// parseSelectCase reads one case line of a select. The colon that ends it
+// is the first top-level ":" that isn't part of ":=", so "case v := <-ch:"
+// splits after the receive. Statements written after the colon on the
+// same line are kept, unparsed, in Body.
+
+type SelectCase struct {
+	Comm      ChannelOp // zero for the default case
+	IsDefault bool
+	Body      string // optional: the rest of the line after ":"
+}
+
+func parseSelectCase(s string) (SelectCase, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return SelectCase{}, errEmptyInput
+	}
+	keyword := leadingIdentifier(s)
+	if keyword != "case" && keyword != "default" {
+		return SelectCase{}, fmt.Errorf("not a select case: %q", s)
+	}
+
+	colon := -1
+	for i := 0; colon == -1; i++ {
+		next := indexTopLevel(s[i:], ":")
+		if next == -1 {
+			return SelectCase{}, fmt.Errorf("missing : after %s in %q", keyword, s)
+		}
+		i += next
+		if op, _ := classifyAssignOp(s, i); op == ":" {
+			colon = i
+		}
+	}
+	comm := strings.TrimSpace(s[len(keyword):colon])
+	sc := SelectCase{Body: strings.TrimSpace(s[colon+1:])}
+
+	if keyword == "default" {
+		if comm != "" {
+			return SelectCase{}, fmt.Errorf("unexpected %q after default", comm)
+		}
+		sc.IsDefault = true
+		return sc, nil
+	}
+
+	if comm == "" {
+		return SelectCase{}, fmt.Errorf("missing send or receive after case")
+	}
+	op, err := parseChannelOp(comm)
+	if err != nil {
+		return SelectCase{}, fmt.Errorf("select case must send or receive: %w", err)
+	}
+	sc.Comm = op
+	return sc, nil
+}
+
+// Example usage:
+// parseSelectCase("case v := <-ch:")      // {Comm: {recv, ch, v}}
+// parseSelectCase("case ch <- x:")        // {Comm: {send, ch, x}}
+// parseSelectCase("default:")             // {IsDefault: true}
+// parseSelectCase("case <-done: return")  // {Comm: {recv, done}, Body: "return"}
+// parseSelectCase("case x > 0:")          // error: select case must send or receive
+
+// ============================================================================
+// 10. RANGE CLAUSE
+// ============================================================================
+// EBNF: RangeClause = [ ExpressionList "=" | IdentifierList ":=" ] "range" Expression .
+//
+// parseRangeClause reads what follows "for" in a range loop. Key and
+// Value are empty when the loop doesn't name them, and Op is empty when
+// there are no variables at all ("range ch"). With ":=" the variables are
+// new identifiers; with "=" they can be any assignable expression, such
+// as "m[k]".
+
+type RangeClause struct {
+	Key   string
+	Value string
+	Op    string // ":=", "=", or "" for a bare "range x"
+	Expr  string
+}
+
+func parseRangeClause(s string) (RangeClause, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return RangeClause{}, errEmptyInput
+	}
+
+	var rc RangeClause
+	rest := s
+	if leadingIdentifier(s) != "range" {
+		start, end := indexTopLevel(s, ":="), 0
+		if start != -1 {
+			end = start + len(":=")
+		} else if start, end = assignOpIndex(s); start == -1 || s[start:end] != "=" {
+			return RangeClause{}, fmt.Errorf("not a range clause: %q", s)
+		}
+		rc.Op = s[start:end]
+		rest = strings.TrimSpace(s[end:])
+		if leadingIdentifier(rest) != "range" {
+			return RangeClause{}, fmt.Errorf("missing range after %s in %q", rc.Op, s)
+		}
+
+		var vars []string
+		var err error
+		if rc.Op == ":=" {
+			vars, err = parseIdentifierList(s[:start])
+		} else {
+			vars, err = splitExpressionList(s[:start])
+		}
+		if err != nil {
+			return RangeClause{}, fmt.Errorf("invalid range variables: %w", err)
+		}
+		switch len(vars) {
+		case 2:
+			rc.Value = vars[1]
+			fallthrough
+		case 1:
+			rc.Key = vars[0]
+		default:
+			return RangeClause{}, fmt.Errorf("range clause permits at most two variables, got %d", len(vars))
+		}
+	}
+
+	rc.Expr = strings.TrimSpace(rest[len("range"):])
+	if rc.Expr == "" {
+		return RangeClause{}, fmt.Errorf("missing expression after range")
+	}
+	return rc, nil
+}
+
+// Example usage:
+// parseRangeClause("i, v := range xs")  // {Key: i, Value: v, Op: ":=", Expr: xs}
+// parseRangeClause("k := range m")      // {Key: k, Op: ":=", Expr: m}
+// parseRangeClause("range ch")          // {Expr: ch}
+// parseRangeClause("m[k] = range keys") // {Key: m[k], Op: "=", Expr: keys}
+// parseRangeClause("i := 0")            // error: missing range after :=
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGoAndDeferStatements(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) (FunctionCall, error)
+		input string
+		want  FunctionCall
+	}{
+		{"go", parseGoStatement, "go doWork(1)", FunctionCall{Name: "doWork", Arguments: []string{"1"}}},
+		{"defer", parseDeferStatement, "defer file.Close()", FunctionCall{Name: "file.Close", Arguments: []string{}}},
+		{"defer with args", parseDeferStatement, "  defer wg.Add(-1, x)", FunctionCall{Name: "wg.Add", Arguments: []string{"-1", "x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		parse func(string) (FunctionCall, error)
+		input string
+	}{
+		{parseGoStatement, "go x + 1"},
+		{parseGoStatement, "go f(x) + g(y)"},
+		{parseGoStatement, "gopher(1)"},
+		{parseGoStatement, "defer f()"},
+		{parseDeferStatement, "defer"},
+		{parseDeferStatement, "defer 1(2)"},
+	}
+	for _, tt := range errorCases {
+		t.Run("error "+tt.input, func(t *testing.T) {
+			if _, err := tt.parse(tt.input); err == nil {
+				t.Errorf("expected an error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseReturnStatement(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"return", []string{}},
+		{"return err", []string{"err"}},
+		{"return a, b", []string{"a", "b"}},
+		{`return a, f(x, y), "z,z"`, []string{"a", "f(x, y)", `"z,z"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseReturnStatement(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Values, tt.want) {
+				t.Errorf("got %q want %q", got.Values, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"returns x", "x := 1", "return f(a"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseReturnStatement(input); err == nil {
+				t.Errorf("parseReturnStatement(%q) expected an error", input)
+			}
+		})
+	}
+
+	for _, tt := range []struct {
+		input   string
+		wantPos int
+	}{
+		{"return a,,b", 9},   // double comma
+		{"return a, b,", 12}, // trailing comma
+		{"return ,", 7},
+		{"  return a, , b", 12},
+		{"return \u00e9,", 9},
+	} {
+		t.Run("empty value "+tt.input, func(t *testing.T) {
+			_, err := parseReturnStatement(tt.input)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("parseReturnStatement(%q) = %v, want a *ParseError", tt.input, err)
+			}
+			if pe.Pos != tt.wantPos {
+				t.Errorf("Pos = %d want %d (%v)", pe.Pos, tt.wantPos, err)
+			}
+		})
+	}
+}
+
+func TestIsValidLabel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"Loop", true},
+		{"retry2", true},
+		{"_outer", true},
+		{"for", false},
+		{"return", false},
+		{"123", false},
+		{"my-label", false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidLabel(tt.input); got != tt.want {
+			t.Errorf("isValidLabel(%q) = %v want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseGotoStatement(t *testing.T) {
+	for input, want := range map[string]string{
+		"goto Done":     "Done",
+		"  goto retry2": "retry2",
+		"goto\t_end":    "_end",
+	} {
+		got, err := parseGotoStatement(input)
+		if err != nil || got != want {
+			t.Errorf("parseGotoStatement(%q) = %q, %v want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{
+		"goto",          // no label
+		"goto 123",      // not an identifier
+		"goto for",      // keyword
+		"goto a b",      // more than one label
+		"gotoDone",      // not the goto keyword
+		"break Done",    // a different statement
+		"goto my-label", // not an identifier
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseGotoStatement(input); err == nil {
+				t.Errorf("parseGotoStatement(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseLabeledStatement(t *testing.T) {
+	tests := []struct {
+		input, wantLabel, wantRest string
+	}{
+		{"Loop:", "Loop", ""},
+		{"Loop: for i := range xs {", "Loop", "for i := range xs {"},
+		{"outer :\tswitch x {", "outer", "switch x {"},
+		{"Done: return nil", "Done", "return nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			label, rest, err := parseLabeledStatement(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if label != tt.wantLabel || rest != tt.wantRest {
+				t.Errorf("got %q, %q want %q, %q", label, rest, tt.wantLabel, tt.wantRest)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"x := 1",      // short variable declaration
+		"f(x)",        // no label
+		"default:",    // keyword
+		"case 1:",     // keyword
+		"123: x++",    // not an identifier
+		": x++",       // missing label
+		"a.b: return", // qualified name
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, _, err := parseLabeledStatement(input); err == nil {
+				t.Errorf("parseLabeledStatement(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestClassifyStatement(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantKind string
+		wantNode bool
+	}{
+		{"for i := 0; i < 10; i++ {", "for", true},
+		{"if err != nil {", "if", true},
+		{"switch v := x.(type) {", "switch", true},
+		{"go worker(jobs)", "go", true},
+		{"defer file.Close()", "defer", true},
+		{"return a, b", "return", true},
+		{"var x int", "var", true},
+		{"const Pi = 3.14", "const", true},
+		{"type T int", "type", true},
+		{"import \"fmt\"", "import", true},
+		{"import (\n\"fmt\"\n\"os\"\n)", "import", true},
+		{"Loop:", "label", true},
+		{"Loop: for {", "label", true},
+		{"package main", "package", true},
+		{"goto Done", "goto", true},
+		{"fmt.Println(x)", "expression", true},
+		{"format(x)", "expression", true},
+		{"  x = y + 1  ", "expression", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			kind, node, err := ClassifyStatement(tt.line)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if kind != tt.wantKind {
+				t.Errorf("kind got %q want %q", kind, tt.wantKind)
+			}
+			if (node != nil) != tt.wantNode {
+				t.Errorf("node got %v, want a node: %v", node, tt.wantNode)
+			}
+		})
+	}
+
+	for _, line := range []string{"", "   ", "go x + 1", "package 123", "goto for", "var 1x int", "import fmt", "import (", "type T"} {
+		t.Run("error "+line, func(t *testing.T) {
+			if _, _, err := ClassifyStatement(line); err == nil {
+				t.Errorf("ClassifyStatement(%q) expected an error", line)
+			}
+		})
+	}
+}
+
+func TestParseShortVarDecl(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ShortVarDecl
+	}{
+		{"x := 5", ShortVarDecl{Names: []string{"x"}, Value: "5"}},
+		{"a, b := f()", ShortVarDecl{Names: []string{"a", "b"}, Value: "f()"}},
+		{"_, err := os.Open(\"x\")", ShortVarDecl{Names: []string{"_", "err"}, Value: "os.Open(\"x\")"}},
+		{"s:=\"a := b\"", ShortVarDecl{Names: []string{"s"}, Value: "\"a := b\""}},
+		{"ok := a == b", ShortVarDecl{Names: []string{"ok"}, Value: "a == b"}},
+		{"f := func(x int) { y := x }", ShortVarDecl{Names: []string{"f"}, Value: "func(x int) { y := x }"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseShortVarDecl(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"x = 5", "x :=", "1x := 5", "a.b := 1", "a, := 1", "for := 1", ":= 1",
+		"x += 1", "a == b", "x = y := 1", `s = "a := b"`, "m[k:=1] = 2",
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseShortVarDecl(input); err == nil {
+				t.Errorf("parseShortVarDecl(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestClassifyStatementSuggestsKeyword(t *testing.T) {
+	_, _, err := ClassifyStatement("fro x := range xs {")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "for"?`) {
+		t.Errorf("got error %v want a suggestion of for", err)
+	}
+
+	// A near-keyword used as an ordinary name is still an expression
+	for _, line := range []string{"fro(x)", "fro := 1", "fro"} {
+		if kind, _, err := ClassifyStatement(line); err != nil || kind != "expression" {
+			t.Errorf("ClassifyStatement(%q) = %q, %v want expression", line, kind, err)
+		}
+	}
+}
+
+func TestParseIfStatement(t *testing.T) {
+	tests := []struct {
+		input string
+		want  IfStatement
+	}{
+		{"if x > 0 { }", IfStatement{Condition: "x > 0"}},
+		{"if ready {", IfStatement{Condition: "ready"}},
+		{"if x := f(); x > 0 { }", IfStatement{Init: "x := f()", Condition: "x > 0"}},
+		{"if v, ok := m[k]; ok {", IfStatement{Init: "v, ok := m[k]", Condition: "ok"}},
+		{"if strings.HasPrefix(s, \"{\") {", IfStatement{Condition: "strings.HasPrefix(s, \"{\")"}},
+		{"if (T{}) == x {", IfStatement{Condition: "(T{}) == x"}},
+		{"if err != nil { return err } else { x++ }", IfStatement{Condition: "err != nil", HasElse: true}},
+		{"if a { } else if b { }", IfStatement{Condition: "a", HasElse: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseIfStatement(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"for x {", "iffy {", "if x > 0", "if {", "if x := f(); {"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseIfStatement(input); err == nil {
+				t.Errorf("parseIfStatement(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseSwitchStatement(t *testing.T) {
+	tests := []struct {
+		input string
+		want  SwitchStatement
+	}{
+		{"switch x { }", SwitchStatement{Tag: "x"}},
+		{"switch f(a, b) {", SwitchStatement{Tag: "f(a, b)"}},
+		{"switch x := f(); x { }", SwitchStatement{Init: "x := f()", Tag: "x"}},
+		{"switch { }", SwitchStatement{}},
+		{"switch x := f(); {", SwitchStatement{Init: "x := f()"}},
+		{"switch v := x.(type) { }", SwitchStatement{Tag: "v := x.(type)", IsTypeSwitch: true}},
+		{"switch x.(type) {", SwitchStatement{Tag: "x.(type)", IsTypeSwitch: true}},
+		{"switch y := g(); v := y.(type) {", SwitchStatement{Init: "y := g()", Tag: "v := y.(type)", IsTypeSwitch: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSwitchStatement(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"if x {", "switcher {", "switch x", "switch x := f() {"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseSwitchStatement(input); err == nil {
+				t.Errorf("parseSwitchStatement(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseAssignment(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Assignment
+	}{
+		{"a = 1", Assignment{LHS: []string{"a"}, RHS: []string{"1"}, Op: "="}},
+		{"a, b = b, a", Assignment{LHS: []string{"a", "b"}, RHS: []string{"b", "a"}, Op: "="}},
+		{"_, err = f()", Assignment{LHS: []string{"_", "err"}, RHS: []string{"f()"}, Op: "="}},
+		{"m[k], p.x = g(a, b), \"=\"", Assignment{LHS: []string{"m[k]", "p.x"}, RHS: []string{"g(a, b)", "\"=\""}, Op: "="}},
+		{"ok = a == b", Assignment{LHS: []string{"ok"}, RHS: []string{"a == b"}, Op: "="}},
+		{"x += 1", Assignment{LHS: []string{"x"}, RHS: []string{"1"}, Op: "+="}},
+		{"x -= y", Assignment{LHS: []string{"x"}, RHS: []string{"y"}, Op: "-="}},
+		{"x <<= 2", Assignment{LHS: []string{"x"}, RHS: []string{"2"}, Op: "<<="}},
+		{"x >>= 2", Assignment{LHS: []string{"x"}, RHS: []string{"2"}, Op: ">>="}},
+		{"mask &^= bit", Assignment{LHS: []string{"mask"}, RHS: []string{"bit"}, Op: "&^="}},
+		{"x ^= 1", Assignment{LHS: []string{"x"}, RHS: []string{"1"}, Op: "^="}},
+		{"f(a == b)[0] = 1", Assignment{LHS: []string{"f(a == b)[0]"}, RHS: []string{"1"}, Op: "="}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseAssignment(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"a == b",    // comparison, not assignment
+		"a != b",    // comparison
+		"a <= b",    // comparison
+		"x := 1",    // short variable declaration
+		"= 1",       // missing left side
+		"x =",       // missing right side
+		"a, = 1",    // empty element
+		"a, b += 1", // compound with two operands
+		"a, b = 1, 2, 3",
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseAssignment(input); err == nil {
+				t.Errorf("parseAssignment(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestClassifyAssignOp(t *testing.T) {
+	tests := []struct {
+		s         string
+		i         int
+		wantOp    string
+		wantWidth int
+	}{
+		{"x = 1", 2, "=", 1},
+		{"=1", 0, "=", 1},
+		{"a == b", 2, "==", 2},
+		{"a == b", 3, "==", 1}, // second half
+		{"a==b", 1, "==", 2},
+		{"x := f()", 2, ":=", 2},
+		{"x := f()", 3, ":=", 1},
+		{"x:=1", 1, ":=", 2},
+		{"n += 2", 3, "+=", 1},
+		{"n+=2", 2, "+=", 1},
+		{"a >= b", 3, ">=", 1},
+		{"a<=b", 2, "<=", 1},
+		{"a != b", 3, "!=", 1},
+		{"x <<= 2", 4, "<<=", 1},
+		{"x &^= m", 4, "&^=", 1},
+		{"x =-1", 2, "=", 1},
+		{"case 1:", 6, ":", 1},
+		{"x = 1", 0, "", 0},  // not at '=' or ':'
+		{"x = 1", 9, "", 0},  // out of range
+		{"x = 1", -1, "", 0}, // out of range
+	}
+
+	for _, tt := range tests {
+		op, width := classifyAssignOp(tt.s, tt.i)
+		if op != tt.wantOp || width != tt.wantWidth {
+			t.Errorf("classifyAssignOp(%q, %d) = %q, %d, want %q, %d", tt.s, tt.i, op, width, tt.wantOp, tt.wantWidth)
+			continue
+		}
+		if op != "" && tt.s[tt.i+width-len(op):tt.i+width] != op {
+			t.Errorf("classifyAssignOp(%q, %d): %q is not at s[i+width-len(op):i+width]", tt.s, tt.i, op)
+		}
+	}
+}
+
+func TestParseSelectCase(t *testing.T) {
+	tests := []struct {
+		input string
+		want  SelectCase
+	}{
+		{"case v := <-ch:", SelectCase{Comm: ChannelOp{Direction: ChanRecv, Channel: "ch", Value: "v"}}},
+		{"case v, ok = <-results[i]:", SelectCase{Comm: ChannelOp{Direction: ChanRecv, Channel: "results[i]", Value: "v, ok"}}},
+		{"case <-time.After(time.Second):", SelectCase{Comm: ChannelOp{Direction: ChanRecv, Channel: "time.After(time.Second)"}}},
+		{"case ch <- x:", SelectCase{Comm: ChannelOp{Direction: ChanSend, Channel: "ch", Value: "x"}}},
+		{`case out <- m["a:b"]:`, SelectCase{Comm: ChannelOp{Direction: ChanSend, Channel: "out", Value: `m["a:b"]`}}},
+		{"case out <- xs[1:]:", SelectCase{Comm: ChannelOp{Direction: ChanSend, Channel: "out", Value: "xs[1:]"}}},
+		{"default:", SelectCase{IsDefault: true}},
+		{"default :", SelectCase{IsDefault: true}},
+		{"case <-done: return", SelectCase{Comm: ChannelOp{Direction: ChanRecv, Channel: "done"}, Body: "return"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSelectCase(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"x := <-ch",       // not a case line
+		"select {",        // not a case line
+		"case v := <-ch",  // missing colon
+		"case:",           // no communication
+		"case x > 0:",     // not a send or receive
+		"default x:",      // default takes nothing
+		"defaults:",       // not the default keyword
+		"case a == <-ch:", // a comparison, not a receive
+		"case x >= <-ch:", // a comparison, not a receive
+		"case x += <-ch:", // a compound assignment, not a receive
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseSelectCase(input); err == nil {
+				t.Errorf("parseSelectCase(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseRangeClause(t *testing.T) {
+	tests := []struct {
+		input string
+		want  RangeClause
+	}{
+		{"k := range m", RangeClause{Key: "k", Op: ":=", Expr: "m"}},
+		{"i, v := range xs", RangeClause{Key: "i", Value: "v", Op: ":=", Expr: "xs"}},
+		{"_, v := range f(a, b)", RangeClause{Key: "_", Value: "v", Op: ":=", Expr: "f(a, b)"}},
+		{"range ch", RangeClause{Expr: "ch"}},
+		{"range 10", RangeClause{Expr: "10"}},
+		{"i = range xs", RangeClause{Key: "i", Op: "=", Expr: "xs"}},
+		{"m[k], p.v = range pairs", RangeClause{Key: "m[k]", Value: "p.v", Op: "=", Expr: "pairs"}},
+		{"k:=range m[\"a:=b\"]", RangeClause{Key: "k", Op: ":=", Expr: "m[\"a:=b\"]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseRangeClause(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"i := 0",             // no range
+		"xs",                 // no range
+		"range",              // no expression
+		"k := range",         // no expression
+		"x += range xs",      // compound assignment
+		"a, b, c := range m", // too many variables
+		"m[k] := range keys", // := needs identifiers
+		"func := range xs",   // keyword
+		"ranger := f()",      // "range" must be the keyword
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseRangeClause(input); err == nil {
+				t.Errorf("parseRangeClause(%q) expected an error", input)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// TOKENIZER - Splitting source text into Go tokens
+// ============================================================================
+//
+// Based on: https://go.dev/ref/spec#Tokens
+// The parsers above work on whole strings; Tokenize is the lexical layer
+// underneath, turning "x := f(1)" into x, :=, f, (, 1, ).
+
+type TokenKind string
+
+const (
+	TokenIdent    TokenKind = "identifier"
+	TokenKeyword  TokenKind = "keyword"
+	TokenNumber   TokenKind = "number"
+	TokenString   TokenKind = "string"   // Value includes the quotes, escapes undecoded
+	TokenRune     TokenKind = "rune"     // likewise; the content isn't checked to be one character
+	TokenOperator TokenKind = "operator" // operators and punctuation
+	TokenError    TokenKind = "error"    // Value is the character that couldn't be scanned, or an unterminated literal
+)
+
+type Token struct {
+	Kind   TokenKind
+	Value  string
+	Line   int // 1-based
+	Column int // 1-based, counted in runes; a tab is one column
+}
+
+// operators lists Go's operators and punctuation. Tokenize takes the
+// longest that fits, so "<<=" is preferred over "<<" and "<".
+var operators = []string{
+	"<<=", ">>=", "&^=", "...",
+	"&&", "||", "<-", "++", "--", "==", "!=", "<=", ">=", ":=",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "<<", ">>", "&^",
+	"+", "-", "*", "/", "%", "&", "|", "^", "<", ">", "=", "!", "~",
+	"(", ")", "[", "]", "{", "}", ",", ";", ".", ":",
+}
+
+func Tokenize(input string) ([]Token, error) {
+	var toks []Token
+	scanTokens(input, func(tok Token) { toks = append(toks, tok) })
+
+	if n := len(toks); n > 0 && toks[n-1].Kind == TokenError {
+		return toks[:n-1], tokenError(input, toks[n-1])
+	}
+	return toks, nil
+}
+
+// TokenizeStream is Tokenize with the tokens sent over a channel as they
+// are scanned, for input too large to hold as a slice of tokens. The
+// channel is closed at the end of input; on failure the last token has
+// Kind TokenError. The caller must drain the channel, or the scanning
+// goroutine blocks forever.
+func TokenizeStream(input string) <-chan Token {
+	ch := make(chan Token)
+	go func() {
+		defer close(ch)
+		scanTokens(input, func(tok Token) { ch <- tok })
+	}()
+	return ch
+}
+
+// tokenError is the error Tokenize returns for a TokenError token from
+// input. An unterminated literal is a *ParseError at its opening quote,
+// "unterminated string literal at position 5", Pos being a rune index as
+// for the other parsers.
+func tokenError(input string, tok Token) error {
+	c, _ := utf8.DecodeRuneInString(tok.Value)
+	switch c {
+	case '"', '`':
+		return newParseError(runeOffset(input, tok), "unterminated string literal")
+	case '\'':
+		return newParseError(runeOffset(input, tok), "unterminated rune literal")
+	}
+	return fmt.Errorf("unexpected character %q at line %d, column %d", c, tok.Line, tok.Column)
+}
+
+// runeOffset converts tok's line and column back to a rune index into
+// input.
+func runeOffset(input string, tok Token) int {
+	offset, line := 0, 1
+	for _, c := range input {
+		if line == tok.Line {
+			break
+		}
+		if c == '\n' {
+			line++
+		}
+		offset++
+	}
+	return offset + tok.Column - 1
+}
+
+// scanQuoted reads the string or rune literal at the start of s. An
+// interpreted string ends at the first unescaped double quote and a rune
+// literal at the first unescaped single quote, and neither may contain a
+// newline; a raw string ends at the next backquote and may. It returns
+// the literal with its quotes, or, when it is unterminated, what there is
+// of it up to the end of the line or input.
+func scanQuoted(s string) (lit string, ok bool) {
+	end := skipQuoted(s, 0)
+	if s[0] != '`' {
+		if nl := strings.IndexByte(s, '\n'); nl != -1 && (end == -1 || nl < end) {
+			return s[:nl], false
+		}
+	}
+	if end == -1 {
+		return s, false
+	}
+	return s[:end+1], true
+}
+
+// scanTokens passes each token of input to emit, stopping after a
+// TokenError token if it meets a character it can't scan or a string or
+// rune literal with no closing quote.
+func scanTokens(input string, emit func(Token)) {
+	sc := NewScanner(input)
+	line, col := 1, 1
+
+	// advance consumes text, which must be the next input, updating line
+	// and column as it goes.
+	advance := func(text string) {
+		for _, c := range text {
+			sc.Next()
+			if c == '\n' {
+				line, col = line+1, 1
+			} else {
+				col++
+			}
+		}
+	}
+
+	for !sc.Eof() {
+		c := sc.Peek()
+		tok := Token{Line: line, Column: col}
+		rest := sc.Rest()
+
+		switch {
+		case isWhitespace(c):
+			advance(string(c))
+			continue
+		case isIdentLetter(c):
+			tok.Kind, tok.Value = TokenIdent, leadingIdentifier(rest)
+			if isKeyword(tok.Value) {
+				tok.Kind = TokenKeyword
+			}
+		case isDigit(c) || (c == '.' && len(rest) > 1 && isDigit(rune(rest[1]))):
+			// Integers, floats, and imaginaries: 42, 0xFF, 1_000, .5, 1e-3,
+			// 0x1p-2, 2i
+			tok.Kind, tok.Value = TokenNumber, rest[:numberEnd(rest, 0)]
+		case c == '"' || c == '`' || c == '\'':
+			lit, ok := scanQuoted(rest)
+			if !ok {
+				tok.Kind, tok.Value = TokenError, lit
+				emit(tok)
+				return
+			}
+			tok.Kind, tok.Value = TokenString, lit
+			if c == '\'' {
+				tok.Kind = TokenRune
+			}
+		default:
+			op, ok := longestMatch(rest, operators)
+			if !ok {
+				tok.Kind, tok.Value = TokenError, string(c)
+				emit(tok)
+				return
+			}
+			tok.Kind, tok.Value = TokenOperator, op
+		}
+
+		advance(tok.Value)
+		emit(tok)
+	}
+}
+
+// Example usage:
+// Tokenize("x := f(1)")
+// // [{identifier x 1 1} {operator := 1 3} {identifier f 1 6} {operator ( 1 7}
+// //  {number 1 1 8} {operator ) 1 9}]
+// Tokenize("a\nbc")  // "bc" is at line 2, column 1
+// Tokenize(`s := "hi\"" + x`)  // {string "hi\"" 1 6} is one token
+// Tokenize(`s := "hi`)         // error: unterminated string literal at position 5
+// Tokenize(`q := '"'`)         // {rune '"' 1 6} is one token
+// Tokenize("x := 1e-3")         // {number 1e-3 1 6} is one token
+//
+// for tok := range TokenizeStream(src) {
+// 	if tok.Kind == TokenError { ... }
+// }
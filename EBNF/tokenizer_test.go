@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Run("kinds and values", func(t *testing.T) {
+		got, err := Tokenize("for x := 0x1F; x <<= 2 {")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Token{
+			{TokenKeyword, "for", 1, 1},
+			{TokenIdent, "x", 1, 5},
+			{TokenOperator, ":=", 1, 7},
+			{TokenNumber, "0x1F", 1, 10},
+			{TokenOperator, ";", 1, 14},
+			{TokenIdent, "x", 1, 16},
+			{TokenOperator, "<<=", 1, 18},
+			{TokenNumber, "2", 1, 22},
+			{TokenOperator, "{", 1, 24},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v\nwant %v", got, want)
+		}
+	})
+	t.Run("line and column across lines", func(t *testing.T) {
+		got, err := Tokenize("a\nbc\n\tx\t+ y")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Token{
+			{TokenIdent, "a", 1, 1},
+			{TokenIdent, "bc", 2, 1},
+			{TokenIdent, "x", 3, 2},
+			{TokenOperator, "+", 3, 4},
+			{TokenIdent, "y", 3, 6},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v\nwant %v", got, want)
+		}
+	})
+	t.Run("empty input", func(t *testing.T) {
+		got, err := Tokenize("  \n ")
+		if err != nil || len(got) != 0 {
+			t.Errorf("got %v, %v want no tokens", got, err)
+		}
+	})
+	t.Run("unexpected character", func(t *testing.T) {
+		got, err := Tokenize("x\n  @")
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+		if want := `unexpected character '@' at line 2, column 3`; err.Error() != want {
+			t.Errorf("got error %q want %q", err, want)
+		}
+		if len(got) != 1 {
+			t.Errorf("expected the tokens before the error, got %v", got)
+		}
+	})
+
+	t.Run("string literals", func(t *testing.T) {
+		got, err := Tokenize("s := \"a b\" + `raw\n\"x` + \"say \\\"hi\\\"\"")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Token{
+			{TokenIdent, "s", 1, 1},
+			{TokenOperator, ":=", 1, 3},
+			{TokenString, `"a b"`, 1, 6},
+			{TokenOperator, "+", 1, 12},
+			{TokenString, "`raw\n\"x`", 1, 14},
+			{TokenOperator, "+", 2, 5},
+			{TokenString, `"say \"hi\""`, 2, 7},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v\nwant %v", got, want)
+		}
+	})
+	t.Run("rune literals", func(t *testing.T) {
+		got, err := Tokenize(`q := '"' + '\''`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Token{
+			{TokenIdent, "q", 1, 1},
+			{TokenOperator, ":=", 1, 3},
+			{TokenRune, `'"'`, 1, 6},
+			{TokenOperator, "+", 1, 10},
+			{TokenRune, `'\''`, 1, 12},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v\nwant %v", got, want)
+		}
+	})
+	t.Run("number literals", func(t *testing.T) {
+		for _, lit := range []string{"42", "1_000", "1.5", ".5", "1.", "1e-3", "1E+10", "0x1p-2", "0X1P+2", "2i", "1.5e3i"} {
+			got, err := Tokenize("x = " + lit + " + y")
+			if err != nil {
+				t.Fatalf("Tokenize(%q): unexpected error: %v", lit, err)
+			}
+			want := []Token{
+				{TokenIdent, "x", 1, 1},
+				{TokenOperator, "=", 1, 3},
+				{TokenNumber, lit, 1, 5},
+				{TokenOperator, "+", 1, 6 + len(lit)},
+				{TokenIdent, "y", 1, 8 + len(lit)},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Tokenize(%q)\ngot %v\nwant %v", lit, got, want)
+			}
+		}
+	})
+	t.Run("sign after a hex digit e", func(t *testing.T) {
+		got, err := Tokenize("0x1e-3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Token{
+			{TokenNumber, "0x1e", 1, 1},
+			{TokenOperator, "-", 1, 5},
+			{TokenNumber, "3", 1, 6},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v\nwant %v", got, want)
+		}
+	})
+	for _, tt := range []struct {
+		input string
+		want  string
+	}{
+		{`x := "abc`, "unterminated string literal at position 5"},
+		{`x := "ends in \"`, "unterminated string literal at position 5"},
+		{"f(\n  `raw", "unterminated string literal at position 5"},
+		{"\"line\nbreak\"", "unterminated string literal at position 0"},
+		{`"`, "unterminated string literal at position 0"},
+		{"é := \"x", "unterminated string literal at position 5"},
+		{`r := 'a`, "unterminated rune literal at position 5"},
+		{"r := '\n'", "unterminated rune literal at position 5"},
+	} {
+		t.Run("error "+tt.input, func(t *testing.T) {
+			_, err := Tokenize(tt.input)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Tokenize(%q) error = %v want %s", tt.input, err, tt.want)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Errorf("Tokenize(%q) error is %T, want *ParseError", tt.input, err)
+			}
+		})
+	}
+}
+
+func TestTokenizeStream(t *testing.T) {
+	for _, input := range []string{
+		"for x := 0x1F; x <<= 2 {",
+		"a\nbc\n\tx\t+ y",
+		"",
+		"x\n  @ y",
+		`s := "a" + "b`,
+	} {
+		t.Run(input, func(t *testing.T) {
+			var got []Token
+			for tok := range TokenizeStream(input) {
+				got = append(got, tok)
+			}
+
+			want, err := Tokenize(input)
+			if err != nil {
+				// the stream ends in an error token where Tokenize returns an error
+				if len(got) == 0 || got[len(got)-1].Kind != TokenError {
+					t.Fatalf("got %v, want a final error token", got)
+				}
+				if streamErr := tokenError(input, got[len(got)-1]); streamErr.Error() != err.Error() {
+					t.Errorf("error token says %q, Tokenize says %q", streamErr, err)
+				}
+				got = got[:len(got)-1]
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v\nwant %v", got, want)
+			}
+		})
+	}
+
+	t.Run("error token position", func(t *testing.T) {
+		var last Token
+		for tok := range TokenizeStream("x\n  @") {
+			last = tok
+		}
+		if want := (Token{TokenError, "@", 2, 3}); last != want {
+			t.Errorf("got %v want %v", last, want)
+		}
+	})
+}
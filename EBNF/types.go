@@ -0,0 +1,536 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// TYPES - Translating Go's type grammar
+// ============================================================================
+//
+// Based on: https://go.dev/ref/spec#Types
+
+// ============================================================================
+// 1. TYPE EXPRESSIONS - Recursion through element types
+// ============================================================================
+// EBNF: Type = TypeName [ TypeArgs ] | TypeLit | "(" Type ")" .
+//       TypeArgs = "[" Type { "," Type } [ "," ] "]" .
+//       TypeLit = ArrayType | PointerType | SliceType | MapType | ChannelType | FunctionType .
+//       PointerType = "*" BaseType .
+//       SliceType = "[" "]" ElementType .
+//       ArrayType = "[" ArrayLength "]" ElementType .
+//       MapType = "map" "[" KeyType "]" ElementType .
+//       ChannelType = ( "chan" | "chan" "<-" | "<-" "chan" ) ElementType .
+//       FunctionType = "func" Signature .
+
+type TypeKind string
+
+const (
+	TypeKindNamed   TypeKind = "named"
+	TypeKindPointer TypeKind = "pointer"
+	TypeKindSlice   TypeKind = "slice"
+	TypeKindArray   TypeKind = "array"
+	TypeKindMap     TypeKind = "map"
+	TypeKindChan    TypeKind = "chan"
+	TypeKindFunc    TypeKind = "func"
+)
+
+type ChanDir string
+
+const (
+	ChanBoth ChanDir = "both" // chan T
+	ChanSend ChanDir = "send" // chan<- T
+	ChanRecv ChanDir = "recv" // <-chan T
+)
+
+type TypeSpec struct {
+	Kind      TypeKind
+	Name      string     // Named: the (possibly qualified) type name
+	TypeArgs  []TypeSpec // Named: the type arguments of a generic type, if any
+	Len       string     // Array: the length expression
+	Key       *TypeSpec  // Map: the key type
+	Elem      *TypeSpec  // Pointer, Slice, Array, Map, Chan: the element type
+	Dir       ChanDir    // Chan: the channel direction
+	Signature string     // Func: everything after "func"
+}
+
+func parseTypeSpec(s string) (TypeSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return TypeSpec{}, errEmptyInput
+	}
+
+	switch {
+	case s[0] == '(':
+		// "(" Type ")"
+		if matchingBracket(s, 0) != len(s)-1 {
+			return TypeSpec{}, fmt.Errorf("unbalanced parentheses in type %q", s)
+		}
+		return parseTypeSpec(s[1 : len(s)-1])
+
+	case s[0] == '*':
+		return typeWithElem(TypeSpec{Kind: TypeKindPointer}, s[1:])
+
+	case s[0] == '[':
+		closeIdx := matchingBracket(s, 0)
+		if closeIdx == -1 {
+			return TypeSpec{}, fmt.Errorf("unclosed bracket in type %q", s)
+		}
+		length := strings.TrimSpace(s[1:closeIdx])
+		if length == "" {
+			return typeWithElem(TypeSpec{Kind: TypeKindSlice}, s[closeIdx+1:])
+		}
+		return typeWithElem(TypeSpec{Kind: TypeKindArray, Len: length}, s[closeIdx+1:])
+
+	case strings.HasPrefix(s, "map["):
+		m, err := parseMapType(s)
+		if err != nil {
+			return TypeSpec{}, err
+		}
+		return TypeSpec{Kind: TypeKindMap, Key: &m.Key, Elem: &m.Value}, nil
+
+	case strings.HasPrefix(s, "<-"):
+		rest := strings.TrimSpace(s[len("<-"):])
+		if !strings.HasPrefix(rest, "chan") {
+			return TypeSpec{}, fmt.Errorf("expected chan after <- in type %q", s)
+		}
+		return typeWithElem(TypeSpec{Kind: TypeKindChan, Dir: ChanRecv}, rest[len("chan"):])
+
+	case isKeywordPrefix(s, "chan"):
+		rest := strings.TrimSpace(s[len("chan"):])
+		if strings.HasPrefix(rest, "<-") {
+			return typeWithElem(TypeSpec{Kind: TypeKindChan, Dir: ChanSend}, rest[len("<-"):])
+		}
+		return typeWithElem(TypeSpec{Kind: TypeKindChan, Dir: ChanBoth}, rest)
+
+	case isKeywordPrefix(s, "func"):
+		return TypeSpec{Kind: TypeKindFunc, Signature: strings.TrimSpace(s[len("func"):])}, nil
+	}
+
+	// TypeName = identifier | PackageName "." identifier .
+	var args []TypeSpec
+	if open := strings.IndexByte(s, '['); open != -1 {
+		if matchingBracket(s, open) != len(s)-1 {
+			return TypeSpec{}, fmt.Errorf("invalid type name %q", s)
+		}
+		list, err := splitDelimited(s[open+1:len(s)-1], ',', true)
+		if err != nil {
+			return TypeSpec{}, fmt.Errorf("invalid type arguments in %q: %w", s, err)
+		}
+		if len(list) == 0 {
+			return TypeSpec{}, fmt.Errorf("missing type arguments in %q", s)
+		}
+		for _, arg := range list {
+			a, err := parseTypeSpec(arg)
+			if err != nil {
+				return TypeSpec{}, fmt.Errorf("invalid type argument in %q: %w", s, err)
+			}
+			args = append(args, a)
+		}
+		s = strings.TrimSpace(s[:open])
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 2 {
+		return TypeSpec{}, fmt.Errorf("invalid type name %q", s)
+	}
+	for _, part := range parts {
+		if err := validateIdentifier(part); err != nil {
+			return TypeSpec{}, fmt.Errorf("invalid type name %q: %w", s, err)
+		}
+	}
+	return TypeSpec{Kind: TypeKindNamed, Name: s, TypeArgs: args}, nil
+}
+
+// typeWithElem parses elem as the element type of spec.
+func typeWithElem(spec TypeSpec, elem string) (TypeSpec, error) {
+	e, err := parseTypeSpec(elem)
+	if err != nil {
+		return TypeSpec{}, fmt.Errorf("invalid %s element type: %w", spec.Kind, err)
+	}
+	spec.Elem = &e
+	return spec, nil
+}
+
+// isKeywordPrefix reports whether s starts with the keyword kw as a whole
+// word, so "chan int" matches "chan" but "channel" does not.
+func isKeywordPrefix(s, kw string) bool {
+	if !strings.HasPrefix(s, kw) {
+		return false
+	}
+	if len(s) == len(kw) {
+		return true
+	}
+	next := rune(s[len(kw)])
+	return !isLetter(next) && !isDigit(next) && next != '_'
+}
+
+// Example usage:
+// parseTypeSpec("*int")            // {Kind: pointer, Elem: {Kind: named, Name: "int"}}
+// parseTypeSpec("[]string")        // {Kind: slice, Elem: {Kind: named, Name: "string"}}
+// parseTypeSpec("map[string]int")  // {Kind: map, Key: string, Elem: int}
+// parseTypeSpec("chan<- bool")     // {Kind: chan, Dir: send, Elem: bool}
+// parseTypeSpec("List[int]")       // {Kind: named, Name: "List", TypeArgs: [int]}
+
+// ============================================================================
+// 2. PREDECLARED TYPES
+// ============================================================================
+// See https://go.dev/ref/spec#Predeclared_identifiers
+//
+// Unlike keywords these are ordinary identifiers in the universe block
+// ("int := 3" is legal, if unwise), so isKeyword does not report them.
+// byte and rune are aliases for uint8 and int32, and any for interface{}.
+
+var predeclaredTypes = map[string]bool{
+	"bool": true, "string": true, "error": true, "any": true, "comparable": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"byte": true, "rune": true,
+}
+
+func isPredeclaredType(s string) bool {
+	return predeclaredTypes[s]
+}
+
+// Example usage:
+// isPredeclaredType("int")     // true
+// isPredeclaredType("rune")    // true (alias for int32)
+// isPredeclaredType("MyType")  // false
+
+// ============================================================================
+// 3. ARRAY TYPES
+// ============================================================================
+// EBNF: ArrayType = "[" ArrayLength "]" ElementType .
+//       ArrayLength = Expression .
+//
+// "[...]T" is only valid in composite literals ([...]int{1, 2}), where the
+// compiler counts the elements; it is accepted here with Len "...".
+
+// errSliceType is returned by parseArrayType for "[]T", which is a slice.
+var errSliceType = errors.New("slice type, not an array")
+
+type ArrayType struct {
+	Len  string // length expression: "5", "n", or "..."
+	Elem TypeSpec
+}
+
+func parseArrayType(s string) (ArrayType, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ArrayType{}, errEmptyInput
+	}
+	if !strings.HasPrefix(s, "[") {
+		return ArrayType{}, fmt.Errorf("not an array type: %q", s)
+	}
+
+	closeIdx := matchingBracket(s, 0)
+	if closeIdx == -1 {
+		return ArrayType{}, fmt.Errorf("unclosed bracket in type %q", s)
+	}
+	length := strings.TrimSpace(s[1:closeIdx])
+	if length == "" {
+		return ArrayType{}, fmt.Errorf("%q: %w", s, errSliceType)
+	}
+
+	elem, err := parseTypeSpec(s[closeIdx+1:])
+	if err != nil {
+		return ArrayType{}, fmt.Errorf("invalid array element type: %w", err)
+	}
+	return ArrayType{Len: length, Elem: elem}, nil
+}
+
+// Example usage:
+// parseArrayType("[5]int")       // {Len: "5", Elem: int}
+// parseArrayType("[...]string")  // {Len: "...", Elem: string}
+// parseArrayType("[]int")        // error wrapping errSliceType
+
+// ============================================================================
+// 4. MAP TYPES
+// ============================================================================
+// EBNF: MapType = "map" "[" KeyType "]" ElementType .
+//       KeyType = Type .
+//
+// The key's closing "]" is found with matchingBracket, so a key that is
+// itself bracketed ("map[[2]int]bool") doesn't end at its first "]".
+// Both sides recurse through parseTypeSpec, which handles nested maps.
+
+type MapType struct {
+	Key   TypeSpec
+	Value TypeSpec
+}
+
+func parseMapType(s string) (MapType, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return MapType{}, errEmptyInput
+	}
+	if !strings.HasPrefix(s, "map[") {
+		return MapType{}, fmt.Errorf("not a map type: %q", s)
+	}
+
+	closeIdx := matchingBracket(s, len("map"))
+	if closeIdx == -1 {
+		return MapType{}, fmt.Errorf("unclosed bracket in type %q", s)
+	}
+	key, err := parseTypeSpec(s[len("map["):closeIdx])
+	if err != nil {
+		return MapType{}, fmt.Errorf("invalid map key: %w", err)
+	}
+	value, err := parseTypeSpec(s[closeIdx+1:])
+	if err != nil {
+		return MapType{}, fmt.Errorf("invalid map value: %w", err)
+	}
+	return MapType{Key: key, Value: value}, nil
+}
+
+// Example usage:
+// parseMapType("map[string]int")           // {Key: string, Value: int}
+// parseMapType("map[string]map[int]bool")  // {Key: string, Value: map[int]bool}
+// parseMapType("map[[2]int]bool")          // {Key: [2]int, Value: bool}
+
+// ============================================================================
+// 5. PARAMETER LISTS
+// ============================================================================
+// EBNF: Parameters = "(" [ ParameterList [ "," ] ] ")" .
+//       ParameterList = ParameterDecl { "," ParameterDecl } .
+//       ParameterDecl = [ IdentifierList ] [ "..." ] Type .
+//
+// parseParamList takes the list without its parentheses. Names stay
+// grouped the way they were written, so "b, c string" is one Param. Either
+// every parameter is named or none is ("int, string"); a lone identifier is
+// a type in the second case. "..." may only prefix the type of the final,
+// single-name parameter. An empty list is valid and gives no params.
+
+type Param struct {
+	Names    []string // empty for an unnamed parameter
+	Type     string   // without the "..."
+	Variadic bool
+}
+
+func parseParamList(s string) ([]Param, error) {
+	decls, err := splitDelimited(s, ',', true)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []Param{}
+	var pending []string // bare elements waiting to learn whether they are names
+	for _, decl := range decls {
+		if decl == "" {
+			return nil, fmt.Errorf("empty parameter in %q", strings.TrimSpace(s))
+		}
+		// "name Type" needs a space after the name, so "pkg.T" stays whole
+		name := leadingIdentifier(decl)
+		rest := strings.TrimSpace(decl[len(name):])
+		if name == "" || isKeyword(name) || rest == "" || rest == decl[len(name):] {
+			pending = append(pending, decl)
+			continue
+		}
+
+		names := append(pending, name)
+		pending = nil
+		if _, err := parseIdentifierList(strings.Join(names, ",")); err != nil {
+			return nil, fmt.Errorf("invalid parameter names: %w", err)
+		}
+		params = append(params, Param{Names: names, Type: rest})
+	}
+
+	if len(pending) > 0 {
+		if len(params) > 0 {
+			return nil, fmt.Errorf("missing type for parameters %v", pending)
+		}
+		for _, typ := range pending {
+			params = append(params, Param{Type: typ})
+		}
+	}
+
+	for i := range params {
+		p := &params[i]
+		if typ, ok := strings.CutPrefix(p.Type, "..."); ok {
+			if i != len(params)-1 || len(p.Names) > 1 {
+				return nil, fmt.Errorf("can only use ... with the final parameter")
+			}
+			p.Type, p.Variadic = strings.TrimSpace(typ), true
+		}
+		if _, err := parseTypeSpec(p.Type); err != nil {
+			return nil, fmt.Errorf("invalid parameter type %q: %w", p.Type, err)
+		}
+	}
+	return params, nil
+}
+
+// Example usage:
+// parseParamList("a int, b, c string, rest ...int")
+// // [{Names: [a], Type: int} {Names: [b c], Type: string} {Names: [rest], Type: int, Variadic: true}]
+// parseParamList("int, []string")      // [{Type: int} {Type: []string}]
+// parseParamList("xs ...int, n int")   // error: ... not on the final parameter
+
+// ============================================================================
+// 6. FUNCTION SIGNATURES
+// ============================================================================
+// EBNF: Signature = Parameters [ Result ] .
+//       Result = Parameters | Type .
+//
+// parseFuncSignature takes what follows "func" (and the name, for a
+// declaration), the same text TypeSpec keeps in Signature. A declaration
+// may lead with a type-parameter list, see parseTypeParams. A result list
+// may be named like a parameter list but can't be variadic.
+
+type FuncSig struct {
+	TypeParams []TypeParam // nil unless the function is generic
+	Params     []Param
+	Results    []Param // empty when the function returns nothing
+}
+
+func parseFuncSignature(s string) (FuncSig, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return FuncSig{}, errEmptyInput
+	}
+
+	var typeParams []TypeParam
+	if s[0] == '[' {
+		closeIdx := matchingBracket(s, 0)
+		if closeIdx == -1 {
+			return FuncSig{}, fmt.Errorf("unclosed type parameter list in %q", s)
+		}
+		var err error
+		if typeParams, err = parseTypeParams(s[:closeIdx+1]); err != nil {
+			return FuncSig{}, err
+		}
+		s = strings.TrimSpace(s[closeIdx+1:])
+		if s == "" {
+			return FuncSig{}, fmt.Errorf("missing parameters after type parameters")
+		}
+	}
+	if s[0] != '(' {
+		return FuncSig{}, fmt.Errorf("signature %q must start with (", s)
+	}
+
+	closeIdx := matchingBracket(s, 0)
+	if closeIdx == -1 {
+		return FuncSig{}, fmt.Errorf("unclosed parameter list in %q", s)
+	}
+	params, err := parseParamList(s[1:closeIdx])
+	if err != nil {
+		return FuncSig{}, fmt.Errorf("invalid parameters: %w", err)
+	}
+	sig := FuncSig{TypeParams: typeParams, Params: params, Results: []Param{}}
+
+	result := strings.TrimSpace(s[closeIdx+1:])
+	switch {
+	case result == "":
+		return sig, nil
+	case result[0] == '(' && matchingBracket(result, 0) == len(result)-1:
+		if sig.Results, err = parseParamList(result[1 : len(result)-1]); err != nil {
+			return FuncSig{}, fmt.Errorf("invalid results: %w", err)
+		}
+	default:
+		if _, err := parseTypeSpec(result); err != nil {
+			return FuncSig{}, fmt.Errorf("invalid result type: %w", err)
+		}
+		sig.Results = []Param{{Type: result}}
+	}
+
+	for _, r := range sig.Results {
+		if r.Variadic {
+			return FuncSig{}, fmt.Errorf("result %v can't be variadic", r.Names)
+		}
+	}
+	return sig, nil
+}
+
+// Example usage:
+// parseFuncSignature("(a int) (b string, err error)")
+// // {Params: [{[a] int}], Results: [{[b] string} {[err] error}]}
+// parseFuncSignature("(x, y int) bool")   // {Params: [{[x y] int}], Results: [{Type: bool}]}
+// parseFuncSignature("()")                // {Params: [], Results: []}
+// parseFuncSignature("[T any](xs []T) T")  // {TypeParams: [{[T] any}], Params: [{[xs] []T}], Results: [{Type: T}]}
+
+// ============================================================================
+// 7. TYPE PARAMETERS
+// ============================================================================
+// EBNF: TypeParameters = "[" TypeParamList [ "," ] "]" .
+//       TypeParamList = TypeParamDecl { "," TypeParamDecl } .
+//       TypeParamDecl = IdentifierList TypeConstraint .
+//       TypeConstraint = TypeTerm { "|" TypeTerm } .
+//       TypeTerm = Type | "~" Type .
+//
+// parseTypeParams takes the list with its brackets. Unlike parameters,
+// every type parameter needs a name and a constraint, so "T, U any" is one
+// TypeParam with two names. A constraint is a union of terms, each a type
+// or an inline interface, optionally prefixed with "~".
+
+type TypeParam struct {
+	Names      []string
+	Constraint string
+}
+
+func parseTypeParams(s string) ([]TypeParam, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errEmptyInput
+	}
+	if s[0] != '[' || matchingBracket(s, 0) != len(s)-1 {
+		return nil, fmt.Errorf("type parameters %q must be enclosed in []", s)
+	}
+
+	decls, err := splitDelimited(s[1:len(s)-1], ',', true)
+	if err != nil {
+		return nil, err
+	}
+	if len(decls) == 0 {
+		return nil, fmt.Errorf("empty type parameter list")
+	}
+
+	params := []TypeParam{}
+	var pending []string // names waiting for the constraint they share
+	for _, decl := range decls {
+		if decl == "" {
+			return nil, fmt.Errorf("empty type parameter in %q", s)
+		}
+		name := leadingIdentifier(decl)
+		constraint := strings.TrimSpace(decl[len(name):])
+		if constraint == "" {
+			pending = append(pending, decl)
+			continue
+		}
+
+		names := append(pending, name)
+		pending = nil
+		if _, err := parseIdentifierList(strings.Join(names, ",")); err != nil {
+			return nil, fmt.Errorf("invalid type parameter names: %w", err)
+		}
+		if err := validateConstraint(constraint); err != nil {
+			return nil, fmt.Errorf("invalid constraint for %v: %w", names, err)
+		}
+		params = append(params, TypeParam{Names: names, Constraint: constraint})
+	}
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("missing constraint for type parameters %v", pending)
+	}
+	return params, nil
+}
+
+// validateConstraint checks each "|"-separated term of a type constraint.
+// An inline interface is only checked for its braces.
+func validateConstraint(s string) error {
+	terms, err := splitDelimitedList(s, '|')
+	if err != nil {
+		return err
+	}
+	for _, term := range terms {
+		if err := validateDeclType(strings.TrimSpace(strings.TrimPrefix(term, "~"))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Example usage:
+// parseTypeParams("[T any]")                  // [{[T] any}]
+// parseTypeParams("[K comparable, V any]")    // [{[K] comparable} {[V] any}]
+// parseTypeParams("[T, U ~int | ~string]")    // [{[T U] ~int | ~string}]
+// parseTypeParams("[T]")                      // error: missing constraint
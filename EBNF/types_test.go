@@ -0,0 +1,344 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func named(name string) *TypeSpec {
+	return &TypeSpec{Kind: TypeKindNamed, Name: name}
+}
+
+func TestParseTypeSpec(t *testing.T) {
+	tests := []struct {
+		input string
+		want  TypeSpec
+	}{
+		{"int", *named("int")},
+		{"io.Reader", *named("io.Reader")},
+		{"channel", *named("channel")},
+		{"*int", TypeSpec{Kind: TypeKindPointer, Elem: named("int")}},
+		{"[]string", TypeSpec{Kind: TypeKindSlice, Elem: named("string")}},
+		{"[4]byte", TypeSpec{Kind: TypeKindArray, Len: "4", Elem: named("byte")}},
+		{"map[string]int", TypeSpec{Kind: TypeKindMap, Key: named("string"), Elem: named("int")}},
+		{"chan int", TypeSpec{Kind: TypeKindChan, Dir: ChanBoth, Elem: named("int")}},
+		{"chan<- bool", TypeSpec{Kind: TypeKindChan, Dir: ChanSend, Elem: named("bool")}},
+		{"<-chan error", TypeSpec{Kind: TypeKindChan, Dir: ChanRecv, Elem: named("error")}},
+		{"func(int) error", TypeSpec{Kind: TypeKindFunc, Signature: "(int) error"}},
+		{"[]*Foo", TypeSpec{Kind: TypeKindSlice, Elem: &TypeSpec{Kind: TypeKindPointer, Elem: named("Foo")}}},
+		{"map[string][]int", TypeSpec{Kind: TypeKindMap, Key: named("string"),
+			Elem: &TypeSpec{Kind: TypeKindSlice, Elem: named("int")}}},
+		{"(*int)", TypeSpec{Kind: TypeKindPointer, Elem: named("int")}},
+		{"List[int]", TypeSpec{Kind: TypeKindNamed, Name: "List", TypeArgs: []TypeSpec{*named("int")}}},
+		{"pkg.Map[string, []T]", TypeSpec{Kind: TypeKindNamed, Name: "pkg.Map", TypeArgs: []TypeSpec{
+			*named("string"), {Kind: TypeKindSlice, Elem: named("T")}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTypeSpec(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "*", "[]", "map[string", "<-int", "1abc", "a.b.c", "List[]", "List[int", "List[1x]", "List[int]x"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseTypeSpec(input); err == nil {
+				t.Errorf("parseTypeSpec(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestIsPredeclaredType(t *testing.T) {
+	for _, name := range []string{
+		"int", "int8", "int64", "uint", "uint32", "uintptr",
+		"float32", "float64", "complex128", "string", "bool",
+		"byte", "rune", "error", "any",
+	} {
+		if !isPredeclaredType(name) {
+			t.Errorf("isPredeclaredType(%q) = false want true", name)
+		}
+	}
+
+	for _, name := range []string{"MyType", "Int", "int128", "func", "io.Reader", ""} {
+		if isPredeclaredType(name) {
+			t.Errorf("isPredeclaredType(%q) = true want false", name)
+		}
+	}
+
+	if isKeyword("int") {
+		t.Errorf("int is a predeclared type, not a keyword")
+	}
+}
+
+func TestParseArrayType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  ArrayType
+	}{
+		{"[5]int", ArrayType{Len: "5", Elem: *named("int")}},
+		{"[...]string", ArrayType{Len: "...", Elem: *named("string")}},
+		{"[n]T", ArrayType{Len: "n", Elem: *named("T")}},
+		{"[2][3]float64", ArrayType{Len: "2", Elem: TypeSpec{Kind: TypeKindArray, Len: "3", Elem: named("float64")}}},
+		{"[len(xs)]*Node", ArrayType{Len: "len(xs)", Elem: TypeSpec{Kind: TypeKindPointer, Elem: named("Node")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseArrayType(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("slices are rejected distinctly", func(t *testing.T) {
+		_, err := parseArrayType("[]int")
+		if !errors.Is(err, errSliceType) {
+			t.Errorf("got error %v want errSliceType", err)
+		}
+	})
+
+	for _, input := range []string{"int", "[5]", "[5]1x", "[5int"} {
+		t.Run("error "+input, func(t *testing.T) {
+			_, err := parseArrayType(input)
+			if err == nil || errors.Is(err, errSliceType) {
+				t.Errorf("parseArrayType(%q) got error %v want a non-slice error", input, err)
+			}
+		})
+	}
+}
+
+func TestParseMapType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  MapType
+	}{
+		{"map[string]int", MapType{Key: *named("string"), Value: *named("int")}},
+		{"map[string][]int", MapType{Key: *named("string"), Value: TypeSpec{Kind: TypeKindSlice, Elem: named("int")}}},
+		{"map[[2]int]bool", MapType{Key: TypeSpec{Kind: TypeKindArray, Len: "2", Elem: named("int")}, Value: *named("bool")}},
+		{"map[string]map[int]bool", MapType{
+			Key:   *named("string"),
+			Value: TypeSpec{Kind: TypeKindMap, Key: named("int"), Elem: named("bool")},
+		}},
+		{"map[pkg.Key]*pkg.Value", MapType{Key: *named("pkg.Key"), Value: TypeSpec{Kind: TypeKindPointer, Elem: named("pkg.Value")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseMapType(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"[]int", "map[string", "map[string]", "map[]int", "map[[2]int"} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseMapType(input); err == nil {
+				t.Errorf("parseMapType(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseParamList(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []Param
+	}{
+		{"", []Param{}},
+		{"a int", []Param{{Names: []string{"a"}, Type: "int"}}},
+		{"a int, b, c string, rest ...int", []Param{
+			{Names: []string{"a"}, Type: "int"},
+			{Names: []string{"b", "c"}, Type: "string"},
+			{Names: []string{"rest"}, Type: "int", Variadic: true},
+		}},
+		{"x, y float64", []Param{{Names: []string{"x", "y"}, Type: "float64"}}},
+		{"ctx context.Context, fn func(int) error, m map[string][]int,", []Param{
+			{Names: []string{"ctx"}, Type: "context.Context"},
+			{Names: []string{"fn"}, Type: "func(int) error"},
+			{Names: []string{"m"}, Type: "map[string][]int"},
+		}},
+		{"int, []string, chan int", []Param{{Type: "int"}, {Type: "[]string"}, {Type: "chan int"}}},
+		{"format string, args ...any", []Param{
+			{Names: []string{"format"}, Type: "string"},
+			{Names: []string{"args"}, Type: "any", Variadic: true},
+		}},
+		{"...string", []Param{{Type: "string", Variadic: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseParamList(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"rest ...int, n int", // variadic not last
+		"a, b ...int",        // variadic with two names
+		"a int, b",           // b has no type
+		"a, , b int",         // empty parameter
+		"type int",           // keyword as a name
+		"a 1x",               // invalid type
+		"a (int",             // unbalanced
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseParamList(input); err == nil {
+				t.Errorf("parseParamList(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseFuncSignature(t *testing.T) {
+	tests := []struct {
+		input string
+		want  FuncSig
+	}{
+		{"()", FuncSig{Params: []Param{}, Results: []Param{}}},
+		{"(s string)", FuncSig{
+			Params:  []Param{{Names: []string{"s"}, Type: "string"}},
+			Results: []Param{},
+		}},
+		{"(x, y int) bool", FuncSig{
+			Params:  []Param{{Names: []string{"x", "y"}, Type: "int"}},
+			Results: []Param{{Type: "bool"}},
+		}},
+		{"() *Node", FuncSig{Params: []Param{}, Results: []Param{{Type: "*Node"}}}},
+		{"(a int) (b string, err error)", FuncSig{
+			Params:  []Param{{Names: []string{"a"}, Type: "int"}},
+			Results: []Param{{Names: []string{"b"}, Type: "string"}, {Names: []string{"err"}, Type: "error"}},
+		}},
+		{"(r io.Reader) (int, error)", FuncSig{
+			Params:  []Param{{Names: []string{"r"}, Type: "io.Reader"}},
+			Results: []Param{{Type: "int"}, {Type: "error"}},
+		}},
+		{"(format string, args ...any) (n int, err error)", FuncSig{
+			Params: []Param{
+				{Names: []string{"format"}, Type: "string"},
+				{Names: []string{"args"}, Type: "any", Variadic: true},
+			},
+			Results: []Param{{Names: []string{"n"}, Type: "int"}, {Names: []string{"err"}, Type: "error"}},
+		}},
+		{"(f func(int) bool) func() error", FuncSig{
+			Params:  []Param{{Names: []string{"f"}, Type: "func(int) bool"}},
+			Results: []Param{{Type: "func() error"}},
+		}},
+		{"[T, U any](xs []T, f func(T) U) []U", FuncSig{
+			TypeParams: []TypeParam{{Names: []string{"T", "U"}, Constraint: "any"}},
+			Params: []Param{
+				{Names: []string{"xs"}, Type: "[]T"},
+				{Names: []string{"f"}, Type: "func(T) U"},
+			},
+			Results: []Param{{Type: "[]U"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseFuncSignature(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		"int",               // no parameter list
+		"(a int",            // unclosed
+		"(a ...int, b int)", // variadic not last
+		"() (...int)",       // variadic result
+		"() 1x",             // invalid result type
+		"() (a int, b)",     // b has no type
+		"[T any]",           // type parameters but no parameters
+		"[T](x T)",          // T has no constraint
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseFuncSignature(input); err == nil {
+				t.Errorf("parseFuncSignature(%q) expected an error", input)
+			}
+		})
+	}
+}
+
+func TestParseTypeParams(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []TypeParam
+	}{
+		{"[T any]", []TypeParam{{Names: []string{"T"}, Constraint: "any"}}},
+		{"[T, U any]", []TypeParam{{Names: []string{"T", "U"}, Constraint: "any"}}},
+		{"[K comparable, V any]", []TypeParam{
+			{Names: []string{"K"}, Constraint: "comparable"},
+			{Names: []string{"V"}, Constraint: "any"},
+		}},
+		{"[N ~int | ~float64]", []TypeParam{{Names: []string{"N"}, Constraint: "~int | ~float64"}}},
+		{"[S interface{ ~[]E }, E any,]", []TypeParam{
+			{Names: []string{"S"}, Constraint: "interface{ ~[]E }"},
+			{Names: []string{"E"}, Constraint: "any"},
+		}},
+		{"[R io.Reader]", []TypeParam{{Names: []string{"R"}, Constraint: "io.Reader"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTypeParams(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("non-generic signature", func(t *testing.T) {
+		sig, err := parseFuncSignature("(x int) int")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sig.TypeParams != nil {
+			t.Errorf("got type params %+v, want nil", sig.TypeParams)
+		}
+	})
+
+	for _, input := range []string{
+		"T any",         // no brackets
+		"[]",            // empty list
+		"[T]",           // no constraint
+		"[T, U]",        // no constraint for either
+		"[1T any]",      // invalid name
+		"[T ~]",         // empty term
+		"[T int |]",     // empty union term
+		"[T interface]", // interface without a body
+	} {
+		t.Run("error "+input, func(t *testing.T) {
+			if _, err := parseTypeParams(input); err == nil {
+				t.Errorf("parseTypeParams(%q) expected an error", input)
+			}
+		})
+	}
+}
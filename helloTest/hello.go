@@ -1,14 +1,142 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
-const englishHelloPrefix = "Hello, "
+const (
+	english            = "en"
+	englishHelloPrefix = "Hello, "
+)
+
+var greetingPrefixes = map[string]string{
+	english: englishHelloPrefix,
+	"es":    "Hola, ",
+	"fr":    "Bonjour, ",
+}
+
+var defaultNames = map[string]string{
+	english: "World",
+	"es":    "Mundo",
+	"fr":    "le Monde",
+}
+
+func RegisterGreeting(language, prefix, defaultName string) {
+	greetingPrefixes[language] = prefix
+	defaultNames[language] = defaultName
+}
 
 func Hello(name string) string {
+	return HelloIn(name, english)
+}
+
+func HelloIn(name, language string) string {
+	prefix, ok := greetingPrefixes[language]
+	if !ok {
+		language, prefix = english, englishHelloPrefix
+	}
+	if name == "" {
+		name = defaultNames[language]
+	}
+	return prefix + name
+}
+
+// HelloByTime greets by time of day: morning for hours 0-11, afternoon for
+// 12-17, and evening for 18-23. Hours outside 0-23 are an error rather than
+// being clamped, since they usually mean a bug in the caller.
+func HelloByTime(name string, hour int) (string, error) {
+	if name == "" {
+		name = defaultNames[english]
+	}
+	switch {
+	case hour < 0 || hour > 23:
+		return "", fmt.Errorf("invalid hour %d, want 0-23", hour)
+	case hour < 12:
+		return "Good morning, " + name, nil
+	case hour < 18:
+		return "Good afternoon, " + name, nil
+	}
+	return "Good evening, " + name, nil
+}
+
+func HelloWithDefault(name, def string) string {
+	if name == "" {
+		name = def
+	}
+	return Hello(name)
+}
+
+// HelloTitled capitalizes only the first rune of name, so "alice" becomes
+// "Alice" and "mary ann" becomes "Mary ann".
+func HelloTitled(name string) string {
 	if name == "" {
-		name = "Worl"
+		return Hello(name)
+	}
+	first, size := utf8.DecodeRuneInString(name)
+	return Hello(string(unicode.ToTitle(first)) + name[size:])
+}
+
+func HelloWithSuffix(name, suffix string) string {
+	return Hello(name) + suffix
+}
+
+func HelloMany(names []string) string {
+	switch len(names) {
+	case 0:
+		return Hello("")
+	case 1:
+		return Hello(names[0])
 	}
-	return englishHelloPrefix + name
+	size := len(englishHelloPrefix) + len(" and ") + 2*(len(names)-2)
+	for _, name := range names {
+		size += len(name)
+	}
+
+	var b strings.Builder
+	b.Grow(size)
+	b.WriteString(englishHelloPrefix)
+	last := len(names) - 1
+	for i, name := range names[:last] {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+	}
+	b.WriteString(" and ")
+	b.WriteString(names[last])
+	return b.String()
+}
+
+type GreetingBuilder struct {
+	language string
+	name     string
+	suffix   string
+}
+
+func NewGreeting() *GreetingBuilder {
+	return &GreetingBuilder{language: english}
+}
+
+func (g *GreetingBuilder) Language(language string) *GreetingBuilder {
+	g.language = language
+	return g
+}
+
+func (g *GreetingBuilder) Name(name string) *GreetingBuilder {
+	g.name = name
+	return g
+}
+
+func (g *GreetingBuilder) Suffix(suffix string) *GreetingBuilder {
+	g.suffix = suffix
+	return g
+}
+
+func (g *GreetingBuilder) Build() string {
+	return HelloIn(g.name, g.language) + g.suffix
 }
 
 func main() {
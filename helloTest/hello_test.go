@@ -1,6 +1,10 @@
 package main
 
-import "testing"
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestHello(t *testing.T) {
 	t.Run("saying hello to people", func(t *testing.T) {
@@ -20,3 +24,269 @@ func TestHello(t *testing.T) {
 		}
 	})
 }
+
+func TestHelloMany(t *testing.T) {
+	t.Run("no names greets the World", func(t *testing.T) {
+		got := HelloMany(nil)
+		want := "Hello, World"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("one name matches Hello", func(t *testing.T) {
+		got := HelloMany([]string{"Alice"})
+		want := Hello("Alice")
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("two names are joined with and", func(t *testing.T) {
+		got := HelloMany([]string{"Alice", "Bob"})
+		want := "Hello, Alice and Bob"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("three or more names use commas then and", func(t *testing.T) {
+		got := HelloMany([]string{"Alice", "Bob", "Carol", "Dave"})
+		want := "Hello, Alice, Bob, Carol and Dave"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("matches the simple join for many sizes", func(t *testing.T) {
+		for _, n := range []int{2, 3, 10, 1000} {
+			names := makeNames(n)
+			got := HelloMany(names)
+			want := helloManyJoin(names)
+
+			if got != want {
+				t.Errorf("%d names: got %q want %q", n, got, want)
+			}
+		}
+	})
+}
+
+// helloManyJoin is the straightforward version HelloMany must agree with.
+func helloManyJoin(names []string) string {
+	last := len(names) - 1
+	return englishHelloPrefix + strings.Join(names[:last], ", ") + " and " + names[last]
+}
+
+func makeNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("Name%d", i)
+	}
+	return names
+}
+
+func BenchmarkHelloMany(b *testing.B) {
+	for _, n := range []int{1, 10, 1000} {
+		names := makeNames(n)
+		b.Run(fmt.Sprintf("%d names", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				HelloMany(names)
+			}
+		})
+	}
+}
+
+func TestHelloWithDefault(t *testing.T) {
+	t.Run("empty name uses the default", func(t *testing.T) {
+		got := HelloWithDefault("", "Friend")
+		want := "Hello, Friend"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("empty default falls back to World", func(t *testing.T) {
+		got := HelloWithDefault("", "")
+		want := "Hello, World"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("a name ignores the default", func(t *testing.T) {
+		got := HelloWithDefault("Chris", "Friend")
+		want := "Hello, Chris"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+}
+
+func TestHelloTitled(t *testing.T) {
+	t.Run("lowercase name is capitalized", func(t *testing.T) {
+		got := HelloTitled("alice")
+		want := "Hello, Alice"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("capitalized name is unchanged", func(t *testing.T) {
+		got := HelloTitled("McKenzie")
+		want := "Hello, McKenzie"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("only the first rune changes", func(t *testing.T) {
+		got := HelloTitled("\u00e9mile van dyke")
+		want := "Hello, \u00c9mile van dyke"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("empty name uses the default", func(t *testing.T) {
+		got := HelloTitled("")
+		want := "Hello, World"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+}
+
+func TestHelloWithSuffix(t *testing.T) {
+	t.Run("exclamation suffix", func(t *testing.T) {
+		got := HelloWithSuffix("World", "!")
+		want := "Hello, World!"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("period suffix", func(t *testing.T) {
+		got := HelloWithSuffix("Chris", ".")
+		want := "Hello, Chris."
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("empty suffix behaves like Hello", func(t *testing.T) {
+		got := HelloWithSuffix("", "")
+		want := Hello("")
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+}
+
+func TestHelloIn(t *testing.T) {
+	t.Run("built-in language", func(t *testing.T) {
+		got := HelloIn("Elodie", "fr")
+		want := "Bonjour, Elodie"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("built-in default name", func(t *testing.T) {
+		got := HelloIn("", "es")
+		want := "Hola, Mundo"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("runtime-registered language", func(t *testing.T) {
+		RegisterGreeting("de", "Hallo, ", "Welt")
+
+		got := HelloIn("", "de")
+		want := "Hallo, Welt"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("unknown language falls back to English", func(t *testing.T) {
+		got := HelloIn("", "xx")
+		want := "Hello, World"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+}
+
+func TestHelloByTime(t *testing.T) {
+	tests := []struct {
+		name string
+		hour int
+		want string
+	}{
+		{"Chris", 0, "Good morning, Chris"},
+		{"Chris", 11, "Good morning, Chris"},
+		{"Chris", 12, "Good afternoon, Chris"},
+		{"Chris", 17, "Good afternoon, Chris"},
+		{"Chris", 18, "Good evening, Chris"},
+		{"", 23, "Good evening, World"},
+	}
+
+	for _, tt := range tests {
+		got, err := HelloByTime(tt.name, tt.hour)
+		if err != nil {
+			t.Fatalf("unexpected error for hour %d: %v", tt.hour, err)
+		}
+		if got != tt.want {
+			t.Errorf("hour %d: got %q want %q", tt.hour, got, tt.want)
+		}
+	}
+
+	t.Run("hours outside 0-23 are an error", func(t *testing.T) {
+		for _, hour := range []int{-1, 24} {
+			if _, err := HelloByTime("Chris", hour); err == nil {
+				t.Errorf("expected an error for hour %d", hour)
+			}
+		}
+	})
+}
+
+func TestGreetingBuilder(t *testing.T) {
+	t.Run("all defaults match Hello", func(t *testing.T) {
+		got := NewGreeting().Build()
+		want := Hello("")
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("language, name, and suffix", func(t *testing.T) {
+		got := NewGreeting().Language("es").Name("Mundo").Suffix("!").Build()
+		want := "Hola, Mundo!"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("language only uses its default name", func(t *testing.T) {
+		got := NewGreeting().Language("fr").Build()
+		want := "Bonjour, le Monde"
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+	t.Run("name and suffix in English", func(t *testing.T) {
+		got := NewGreeting().Suffix(".").Name("Chris").Build()
+		want := "Hello, Chris."
+
+		if got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+}